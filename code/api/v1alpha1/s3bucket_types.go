@@ -34,6 +34,61 @@ const (
 	ERROR_STATE = "ERROR"
 )
 
+// Drift policies decide what happens to remote bucket configurations that are not declared in the spec.
+const (
+	// DriftPolicyEnforce removes remote configurations that are not declared in the spec.
+	DriftPolicyEnforce = "Enforce"
+	// DriftPolicyReport keeps remote configurations that are not declared in the spec and reports them.
+	DriftPolicyReport = "Report"
+)
+
+// Identity types that can be provisioned for scoped bucket access.
+const (
+	// IdentityTypeUser provisions an IAM user with access keys.
+	IdentityTypeUser = "User"
+	// IdentityTypeRole provisions an IAM role assumed by the principals of its trust policy.
+	IdentityTypeRole = "Role"
+)
+
+// Condition types reported in S3BucketStatus.Conditions.
+const (
+	// ConditionDrifted is True when the bucket has remote configurations that are not declared in the spec.
+	ConditionDrifted = "Drifted"
+	// ConditionFeaturesSupported is False when the storage backend does not implement features requested in the spec.
+	ConditionFeaturesSupported = "FeaturesSupported"
+	// ConditionConnectionDetailsRendered is False when a connection details template fails to render.
+	ConditionConnectionDetailsRendered = "ConnectionDetailsRendered"
+	// ConditionPaused is True while the PausedAnnotation freezes the reconciliation of the bucket.
+	ConditionPaused = "Paused"
+)
+
+// PausedAnnotation set to "true" freezes the reconciliation of an S3Bucket: the operator makes no
+// remote changes, including the deletion of the bucket, until the annotation is removed.
+const PausedAnnotation = "s3.acme.io/paused"
+
+// Pod label and annotations requesting the connection details of S3Buckets, injected by the Pod webhook.
+const (
+	// InjectLabel set to "true" opts a Pod in to the webhook. Only labeled Pods are sent to it,
+	// so the creation of other Pods never depends on the webhook being available
+	InjectLabel = "s3.acme.io/inject-enabled"
+	// InjectAnnotation lists the S3Buckets, in the namespace of the Pod, to inject: my-bucket[,other]
+	InjectAnnotation = "s3.acme.io/inject"
+	// InjectModeAnnotation selects how they are injected, env (the default) or volume
+	InjectModeAnnotation = "s3.acme.io/inject-mode"
+	// InjectModeEnv adds the ConfigMap and Secrets of the buckets to the environment of every container.
+	InjectModeEnv = "env"
+	// InjectModeVolume mounts the ConfigMap and Secrets of each bucket at /var/run/s3/<bucket>.
+	InjectModeVolume = "volume"
+)
+
+// Targets connection details are written to.
+const (
+	// ConnectionDetailsTargetConfigMap writes the key to the <name>-s3-cm ConfigMap.
+	ConnectionDetailsTargetConfigMap = "ConfigMap"
+	// ConnectionDetailsTargetSecret writes the key to the <name>-s3-connection Secret.
+	ConnectionDetailsTargetSecret = "Secret"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -45,16 +100,498 @@ type S3BucketSpec struct {
 	// Name is the name of the S3 bucket
 	Name string `json:"name,omitempty"` // omitempty is used to avoid issues with Terraform when the field is not set, but it is required for the API
 
-	// Region is the AWS region where the bucket will be created
+	// Region is the AWS region where the bucket will be created, or the location on other providers.
+	// It cannot be changed afterwards
 	Region string `json:"region,omitempty"` // omitempty is used to avoid issues with Terraform when the field is not set, but it is required for the API
 
 	// Locked indicates if the bucket is locked for deletion
 	Locked bool `json:"locked,omitempty"` // omitempty is used to avoid issues with Terraform when the field is not set, but it is required for the API
+
+	// ProviderConfigRef is the name of the S3ProviderConfig used to reach the bucket.
+	// Defaults to the S3ProviderConfig annotated as the cluster default. It cannot be changed afterwards
+	// +optional
+	ProviderConfigRef string `json:"providerConfigRef,omitempty"`
+
+	// Versioning keeps every version of every object in the bucket. Without it, versioning enabled
+	// on the bucket is only suspended under the Enforce drift policy
+	// +optional
+	Versioning bool `json:"versioning,omitempty"`
+
+	// RetentionDays is the default retention of new objects in days. Objects cannot be deleted or
+	// overwritten before it expires. Requires locked on S3, where governance mode object lock is used.
+	// Without it, the default retention of a locked bucket is only removed under the Enforce drift policy
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionDays int32 `json:"retentionDays,omitempty"`
+
+	// Tags are applied to the bucket, as labels on providers that have no tags. Without tags, the
+	// tags of an AWS bucket are only removed under the Enforce drift policy
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Lifecycle expires and transitions objects, reconciled as a whole. Without rules, the rules of
+	// an AWS bucket are only removed under the Enforce drift policy
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	Lifecycle []LifecycleRule `json:"lifecycle,omitempty"`
+
+	// Website enables static website hosting on the bucket
+	// +optional
+	Website *WebsiteSpec `json:"website,omitempty"`
+
+	// Acceleration enables S3 Transfer Acceleration for fast uploads over long distances
+	// +optional
+	Acceleration bool `json:"acceleration,omitempty"`
+
+	// RequesterPays makes the requester, rather than the bucket owner, pay for requests and data transfer
+	// +optional
+	RequesterPays bool `json:"requesterPays,omitempty"`
+
+	// DefaultStorageClass is the storage class clients should use for new objects.
+	// S3 has no bucket-level default, so it is published in the bucket ConfigMap for clients to pick up
+	// +kubebuilder:validation:Enum=STANDARD;REDUCED_REDUNDANCY;STANDARD_IA;ONEZONE_IA;INTELLIGENT_TIERING;GLACIER;GLACIER_IR;DEEP_ARCHIVE
+	// +optional
+	DefaultStorageClass string `json:"defaultStorageClass,omitempty"`
+
+	// Inventory configures S3 Inventory reports, reconciled by ID
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	Inventory []InventoryConfiguration `json:"inventory,omitempty"`
+
+	// Analytics configures storage class analysis, reconciled by ID
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	Analytics []AnalyticsConfiguration `json:"analytics,omitempty"`
+
+	// IntelligentTiering configures the S3 Intelligent-Tiering archive tiers, reconciled by ID
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	IntelligentTiering []IntelligentTieringConfiguration `json:"intelligentTiering,omitempty"`
+
+	// DriftPolicy decides what happens to remote versioning, default retention, inventory, analytics
+	// and intelligent-tiering configurations that are not declared in the spec. Enforce removes them, Report keeps them
+	// and lists them in the Drifted condition. They are checked when the spec changes and at the
+	// drift check interval of the operator
+	// +kubebuilder:validation:Enum=Enforce;Report
+	// +kubebuilder:default=Report
+	// +optional
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+
+	// Access provisions a dedicated IAM identity with least-privilege access to this bucket only.
+	// Its credentials are written to the <name>-s3-credentials Secret and removed on delete
+	// +optional
+	Access *AccessSpec `json:"access,omitempty"`
+
+	// AllowedAccessNamespaces lists the namespaces, besides its own, whose S3BucketAccess
+	// resources may grant access to this bucket and whose S3Buckets may deliver inventory and
+	// analytics reports to it. "*" allows every namespace
+	// +optional
+	AllowedAccessNamespaces []string `json:"allowedAccessNamespaces,omitempty"`
+
+	// ConnectionDetails are additional keys rendered from Go templates over the bucket facts,
+	// for applications expecting their own key names or formats
+	// +listType=map
+	// +listMapKey=key
+	// +optional
+	ConnectionDetails []ConnectionDetail `json:"connectionDetails,omitempty"`
+}
+
+// ConnectionDetail renders one key of the bucket connection details.
+type ConnectionDetail struct {
+	// Key is the ConfigMap or Secret key written
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Key string `json:"key"`
+
+	// Template is a Go template rendered over the bucket facts: .BucketName, .Region, .Endpoint,
+	// .ARN and .URL, plus .Credentials, the keys of the <name>-s3-credentials Secret, for Secret
+	// targets. The functions toJson, b64enc, upper and lower are available, e.g.
+	// s3://{{ .BucketName }} or {"bucket": {{ toJson .BucketName }}}
+	Template string `json:"template"`
+
+	// Target is where the key is written. Only Secret targets can render credentials
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	// +optional
+	Target string `json:"target,omitempty"`
+}
+
+// LifecycleRule expires or transitions the objects under a prefix.
+type LifecycleRule struct {
+	// ID identifies the rule
+	// +kubebuilder:validation:MinLength=1
+	ID string `json:"id"`
+
+	// Prefix restricts the rule to object keys starting with it
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// ExpirationDays deletes objects this many days after their creation
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpirationDays int32 `json:"expirationDays,omitempty"`
+
+	// NoncurrentVersionExpirationDays deletes object versions this many days after they became noncurrent
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NoncurrentVersionExpirationDays int32 `json:"noncurrentVersionExpirationDays,omitempty"`
+
+	// Transitions move objects to cheaper storage classes as they age
+	// +optional
+	Transitions []LifecycleTransition `json:"transitions,omitempty"`
+}
+
+// LifecycleTransition moves objects to another storage class.
+type LifecycleTransition struct {
+	// Days after creation at which objects move
+	// +kubebuilder:validation:Minimum=0
+	Days int32 `json:"days"`
+
+	// StorageClass objects move to
+	// +kubebuilder:validation:Enum=STANDARD_IA;ONEZONE_IA;INTELLIGENT_TIERING;GLACIER;GLACIER_IR;DEEP_ARCHIVE
+	StorageClass string `json:"storageClass"`
+}
+
+// AccessSpec describes the scoped IAM identity provisioned for a bucket.
+// +kubebuilder:validation:XValidation:rule="self.identityType != 'Role' || has(self.trustPolicy)",message="trustPolicy is required for Role identities"
+type AccessSpec struct {
+	// Level is the access granted on the bucket
+	// +kubebuilder:validation:Enum=read;write;admin
+	// +kubebuilder:default=read
+	// +optional
+	Level string `json:"level,omitempty"`
+
+	// IdentityType is the kind of IAM identity to provision. A User gets access keys,
+	// a Role is assumed by the principals allowed by TrustPolicy, e.g. an IRSA service account
+	// +kubebuilder:validation:Enum=User;Role
+	// +kubebuilder:default=User
+	// +optional
+	IdentityType string `json:"identityType,omitempty"`
+
+	// TrustPolicy is the assume-role policy document of a Role identity
+	// +optional
+	TrustPolicy string `json:"trustPolicy,omitempty"`
+}
+
+// BucketReference refers to another S3Bucket resource.
+type BucketReference struct {
+	// Name is the name of the S3Bucket resource
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the S3Bucket resource, defaults to the namespace of the referencing resource
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// InventoryConfiguration describes an S3 Inventory report of the bucket.
+type InventoryConfiguration struct {
+	// ID identifies the inventory configuration on the bucket
+	ID string `json:"id"`
+
+	// Destination is the bucket receiving the inventory reports. Its bucket policy must allow
+	// s3.amazonaws.com to write the reports
+	Destination InventoryDestination `json:"destination"`
+
+	// Frequency is how often reports are generated
+	// +kubebuilder:validation:Enum=Daily;Weekly
+	// +kubebuilder:default=Daily
+	// +optional
+	Frequency string `json:"frequency,omitempty"`
+
+	// IncludedObjectVersions selects whether all object versions or only current ones are listed
+	// +kubebuilder:validation:Enum=All;Current
+	// +kubebuilder:default=Current
+	// +optional
+	IncludedObjectVersions string `json:"includedObjectVersions,omitempty"`
+
+	// Prefix restricts the inventory to objects whose key starts with this prefix
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// OptionalFields are the additional object metadata fields included in the report, e.g. Size or StorageClass
+	// +optional
+	OptionalFields []string `json:"optionalFields,omitempty"`
+}
+
+// InventoryDestination describes where inventory reports are delivered.
+type InventoryDestination struct {
+	// BucketRef is the S3Bucket resource of the destination bucket. A bucket in another namespace
+	// must list this namespace in its spec.allowedAccessNamespaces
+	BucketRef BucketReference `json:"bucketRef"`
+
+	// Prefix is prepended to the report keys in the destination bucket
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Format is the output format of the reports
+	// +kubebuilder:validation:Enum=CSV;ORC;Parquet
+	// +kubebuilder:default=CSV
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+// AnalyticsConfiguration describes a storage class analysis of the bucket.
+type AnalyticsConfiguration struct {
+	// ID identifies the analytics configuration on the bucket
+	ID string `json:"id"`
+
+	// Prefix restricts the analysis to objects whose key starts with this prefix
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Export delivers the daily analysis results as CSV to another bucket
+	// +optional
+	Export *AnalyticsExport `json:"export,omitempty"`
+}
+
+// AnalyticsExport describes where storage class analysis results are delivered.
+type AnalyticsExport struct {
+	// BucketRef is the S3Bucket resource of the destination bucket. A bucket in another namespace
+	// must list this namespace in its spec.allowedAccessNamespaces
+	BucketRef BucketReference `json:"bucketRef"`
+
+	// Prefix is prepended to the exported keys in the destination bucket
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// IntelligentTieringConfiguration describes the archive tiers of S3 Intelligent-Tiering.
+type IntelligentTieringConfiguration struct {
+	// ID identifies the intelligent-tiering configuration on the bucket
+	ID string `json:"id"`
+
+	// Prefix restricts the configuration to objects whose key starts with this prefix
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// ArchiveAccessDays moves objects not accessed for this many days to the Archive Access tier
+	// +kubebuilder:validation:Minimum=90
+	// +optional
+	ArchiveAccessDays int64 `json:"archiveAccessDays,omitempty"`
+
+	// DeepArchiveAccessDays moves objects not accessed for this many days to the Deep Archive Access tier
+	// +kubebuilder:validation:Minimum=180
+	// +optional
+	DeepArchiveAccessDays int64 `json:"deepArchiveAccessDays,omitempty"`
+}
+
+// WebsiteSpec defines the static website hosting configuration of the bucket.
+type WebsiteSpec struct {
+	// IndexDocument is the suffix appended to requests for a directory, e.g. index.html
+	// +kubebuilder:default=index.html
+	// +optional
+	IndexDocument string `json:"indexDocument,omitempty"`
+
+	// ErrorDocument is the object key returned when a 4XX error occurs
+	// +optional
+	ErrorDocument string `json:"errorDocument,omitempty"`
+
+	// RoutingRules are the redirect rules evaluated in order for every website request
+	// +optional
+	RoutingRules []RoutingRule `json:"routingRules,omitempty"`
+
+	// ExternalService creates an ExternalName Service pointing at the website endpoint,
+	// so in-cluster clients can address the site by Service name
+	// +optional
+	ExternalService bool `json:"externalService,omitempty"`
+}
+
+// RoutingRule redirects website requests that match Condition.
+type RoutingRule struct {
+	// Condition restricts the rule to matching requests. An empty condition matches every request
+	// +optional
+	Condition *RoutingRuleCondition `json:"condition,omitempty"`
+
+	// Redirect describes where matching requests are sent
+	Redirect RoutingRuleRedirect `json:"redirect"`
+}
+
+// RoutingRuleCondition describes the requests a RoutingRule applies to.
+type RoutingRuleCondition struct {
+	// KeyPrefixEquals matches object keys starting with this prefix
+	// +optional
+	KeyPrefixEquals string `json:"keyPrefixEquals,omitempty"`
+
+	// HTTPErrorCodeReturnedEquals matches requests failing with this HTTP error code, e.g. 404
+	// +optional
+	HTTPErrorCodeReturnedEquals string `json:"httpErrorCodeReturnedEquals,omitempty"`
+}
+
+// RoutingRuleRedirect describes the redirect response of a RoutingRule.
+type RoutingRuleRedirect struct {
+	// HostName is the host name used in the redirect request
+	// +optional
+	HostName string `json:"hostName,omitempty"`
+
+	// HTTPRedirectCode is the HTTP status code returned with the redirect, e.g. 301
+	// +optional
+	HTTPRedirectCode string `json:"httpRedirectCode,omitempty"`
+
+	// Protocol is the protocol used in the redirect request
+	// +kubebuilder:validation:Enum=http;https
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+
+	// ReplaceKeyPrefixWith replaces the prefix matched by KeyPrefixEquals
+	// +optional
+	ReplaceKeyPrefixWith string `json:"replaceKeyPrefixWith,omitempty"`
+
+	// ReplaceKeyWith replaces the whole object key. Mutually exclusive with ReplaceKeyPrefixWith
+	// +optional
+	ReplaceKeyWith string `json:"replaceKeyWith,omitempty"`
+}
+
+// AccessStatus describes a provisioned IAM identity and the Secret holding its credentials.
+type AccessStatus struct {
+	// IdentityType is the kind of IAM identity, User or Role
+	IdentityType string `json:"identityType"`
+
+	// IdentityName is the name of the IAM user or role
+	IdentityName string `json:"identityName"`
+
+	// IdentityARN is the ARN of the IAM user or role
+	// +optional
+	IdentityARN string `json:"identityARN,omitempty"`
+
+	// SecretName is the name of the Secret holding the credentials
+	SecretName string `json:"secretName"`
+}
+
+// BindingStatus references the Secret projected for the bucket following the Service Binding
+// specification for Kubernetes, https://servicebinding.io.
+type BindingStatus struct {
+	// Name is the name of the binding Secret in the namespace of the bucket
+	Name string `json:"name"`
+}
+
+// Sources of bucket usage
+const (
+	// UsageSourceCloudWatch reads the daily storage metrics that S3 publishes to CloudWatch
+	UsageSourceCloudWatch = "CloudWatch"
+	// UsageSourceListing counts the objects returned by listing the bucket
+	UsageSourceListing = "Listing"
+	// UsageSourceProvider asks the provider of the bucket, e.g. Local walks the bucket directory
+	UsageSourceProvider = "Provider"
+)
+
+// Reasons of retries, classifying the last failure
+const (
+	// RetryReasonRetryable failures are transient: throttling, server errors and timeouts
+	RetryReasonRetryable = "Retryable"
+	// RetryReasonConflict failures depend on a concurrent operation or on the bucket contents,
+	// e.g. a bucket being deleted or not empty
+	RetryReasonConflict = "Conflict"
+	// RetryReasonTerminal failures need a change of the spec or of the credentials, e.g. a name
+	// taken by another account, an invalid configuration or denied access
+	RetryReasonTerminal = "Terminal"
+)
+
+// RetryStatus describes the consecutive failures of a bucket operation.
+type RetryStatus struct {
+	// Operation is the failed operation: create, sync, repair or delete
+	Operation string `json:"operation"`
+
+	// Count is the number of consecutive failed attempts
+	Count int32 `json:"count"`
+
+	// Reason classifies the last failure
+	// +kubebuilder:validation:Enum=Retryable;Conflict;Terminal
+	Reason string `json:"reason"`
+
+	// Message summarizes the last failure
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ObservedGeneration is the spec generation that failed; a newer generation is retried at once
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// CredentialsVersion identifies the provider config and credentials that failed; new ones are
+	// retried at once
+	// +optional
+	CredentialsVersion string `json:"credentialsVersion,omitempty"`
+
+	// NextRetryTime is when the failed operation is retried. It is unset for terminal failures,
+	// which wait for a new generation or new credentials
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// BucketUsage is the storage used by a bucket, collected periodically by the operator.
+type BucketUsage struct {
+	// SizeBytes is the total size of the objects in the bucket
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// ObjectCount is the number of objects in the bucket
+	ObjectCount int64 `json:"objectCount"`
+
+	// Source is how the usage was collected: CloudWatch, Listing or Provider
+	Source string `json:"source"`
+
+	// Truncated is true when the listing stopped at the configured bound; SizeBytes and
+	// ObjectCount are then lower bounds
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// LastUpdated is when the usage was collected
+	LastUpdated metav1.Time `json:"lastUpdated"`
 }
 
 // S3BucketStatus defines the observed state of S3Bucket.
 type S3BucketStatus struct {
 	State string `json:"state,omitempty"`
+
+	// ObservedGeneration is the last spec generation applied to the bucket
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// AccountID is the AWS account that owns the bucket
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+
+	// WebsiteEndpoint is the URL of the static website hosted by the bucket
+	// +optional
+	WebsiteEndpoint string `json:"websiteEndpoint,omitempty"`
+
+	// AccelerationStatus is the observed transfer acceleration state, Enabled or Suspended
+	// +optional
+	AccelerationStatus string `json:"accelerationStatus,omitempty"`
+
+	// RequestPayer is the observed payer of requests, BucketOwner or Requester
+	// +optional
+	RequestPayer string `json:"requestPayer,omitempty"`
+
+	// Access describes the scoped IAM identity provisioned for the bucket
+	// +optional
+	Access *AccessStatus `json:"access,omitempty"`
+
+	// Binding references the servicebinding.io binding Secret of the bucket, making the
+	// S3Bucket a provisioned service that workloads can bind to directly
+	// +optional
+	Binding *BindingStatus `json:"binding,omitempty"`
+
+	// LastDriftCheckTime is when the bucket was last checked for unmanaged configurations
+	// +optional
+	LastDriftCheckTime *metav1.Time `json:"lastDriftCheckTime,omitempty"`
+
+	// Usage is the storage used by the bucket, refreshed at the usage interval of the operator
+	// +optional
+	Usage *BucketUsage `json:"usage,omitempty"`
+
+	// Retry describes the consecutive failures of the bucket and when the failed operation is
+	// retried. It is cleared once the operation succeeds.
+	// +optional
+	Retry *RetryStatus `json:"retry,omitempty"`
+
+	// Conditions describe the latest observations of the bucket
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -62,12 +599,17 @@ type S3BucketStatus struct {
 // +kubebuilder:printcolumn:name="Bucket Name",type="string",JSONPath=".spec.name",description="The name of the S3 bucket"
 // +kubebuilder:printcolumn:name="Region",type="string",JSONPath=".spec.region",description="The AWS region of the S3 bucket"
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`,description="The current state of the S3 bucket"
+// +kubebuilder:printcolumn:name="Account",type=string,JSONPath=`.status.accountID`,description="The AWS account owning the S3 bucket",priority=1
+// +kubebuilder:printcolumn:name="Retries",type=integer,JSONPath=`.status.retry.count`,description="The consecutive failed attempts",priority=1
 
 // S3Bucket is the Schema for the s3buckets API.
 type S3Bucket struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
+	// Spec keeps its region and provider config once set, moving a bucket takes a new one
+	// +kubebuilder:validation:XValidation:rule="(has(self.region) ? self.region : '') == (has(oldSelf.region) ? oldSelf.region : '')",message="region is immutable"
+	// +kubebuilder:validation:XValidation:rule="(has(self.providerConfigRef) ? self.providerConfigRef : '') == (has(oldSelf.providerConfigRef) ? oldSelf.providerConfigRef : '')",message="providerConfigRef is immutable"
 	Spec   S3BucketSpec   `json:"spec,omitempty"`
 	Status S3BucketStatus `json:"status,omitempty"`
 }
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionReady is True when the resource has been fully reconciled.
+const ConditionReady = "Ready"
+
+// S3BucketAccessSpec defines the desired state of S3BucketAccess.
+type S3BucketAccessSpec struct {
+	// BucketRef is the S3Bucket to grant access to. A bucket in another namespace must
+	// list this namespace in its spec.allowedAccessNamespaces
+	BucketRef BucketReference `json:"bucketRef"`
+
+	// Level is the access granted on the bucket
+	// +kubebuilder:validation:Enum=read;write;admin
+	// +kubebuilder:default=read
+	// +optional
+	Level string `json:"level,omitempty"`
+
+	// Prefix restricts access to object keys starting with this prefix
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// SecretName is the name of the Secret receiving the credentials, defaults to <name>-s3-access
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// S3BucketAccessStatus defines the observed state of S3BucketAccess.
+type S3BucketAccessStatus struct {
+	// ObservedGeneration is the last spec generation applied
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BucketName is the name of the S3 bucket access was granted to
+	// +optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// Access describes the IAM identity provisioned for this grant
+	// +optional
+	Access *AccessStatus `json:"access,omitempty"`
+
+	// ProviderConfigRef is the S3ProviderConfig of the bucket when access was granted, empty for
+	// the default one. Access is revoked through it, also once the bucket is gone
+	// +optional
+	ProviderConfigRef string `json:"providerConfigRef,omitempty"`
+
+	// BucketNamespace is the namespace of the bucket when access was granted, set together
+	// with providerConfigRef
+	// +optional
+	BucketNamespace string `json:"bucketNamespace,omitempty"`
+
+	// Conditions describe the latest observations of the grant
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Bucket",type="string",JSONPath=".spec.bucketRef.name",description="The S3Bucket access is granted to"
+// +kubebuilder:printcolumn:name="Level",type="string",JSONPath=".spec.level",description="The access level"
+// +kubebuilder:printcolumn:name="Prefix",type="string",JSONPath=".spec.prefix",description="The key prefix access is limited to"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Whether the credentials are ready"
+
+// S3BucketAccess is the Schema for the s3bucketaccesses API.
+type S3BucketAccess struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S3BucketAccessSpec   `json:"spec,omitempty"`
+	Status S3BucketAccessStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3BucketAccessList contains a list of S3BucketAccess.
+type S3BucketAccessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3BucketAccess `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3BucketAccess{}, &S3BucketAccessList{})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Phases of an S3BucketClaim.
+const (
+	// ClaimPending indicates that the claimed bucket is not ready yet.
+	ClaimPending = "Pending"
+	// ClaimBound indicates that the claim is bound to a ready bucket.
+	ClaimBound = "Bound"
+	// ClaimFailed indicates that the claim cannot be satisfied, e.g. because of a disallowed override.
+	ClaimFailed = "Failed"
+)
+
+// ConditionBound reports whether a claim is bound to a ready bucket.
+const ConditionBound = "Bound"
+
+// S3BucketClaimSpec defines the desired state of S3BucketClaim.
+type S3BucketClaimSpec struct {
+	// ClassName is the S3BucketClass providing the bucket defaults.
+	// Defaults to the class annotated with s3bucketclass.s3.acme.io/is-default-class
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// Overrides are S3Bucket spec fields applied on top of the class defaults.
+	// Only the fields listed in the class allowedOverrides are accepted
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Overrides *runtime.RawExtension `json:"overrides,omitempty"`
+}
+
+// S3BucketClaimStatus defines the observed state of S3BucketClaim.
+type S3BucketClaimStatus struct {
+	// Phase is Pending, Bound or Failed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// ObservedGeneration is the last spec generation applied
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BucketRef is the S3Bucket resource bound to the claim
+	// +optional
+	BucketRef *BucketReference `json:"bucketRef,omitempty"`
+
+	// BucketName is the name of the S3 bucket bound to the claim
+	// +optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// ClassName is the S3BucketClass the bucket was stamped out from. The claim keeps using it
+	// when the default class changes later on
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// ReclaimPolicy is the reclaim policy of the class the bucket was stamped out from. It decides
+	// what happens to the bucket when the claim is deleted, even if the class is gone by then
+	// +optional
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+
+	// Conditions describe the latest observations of the claim
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Class",type="string",JSONPath=".spec.className",description="The S3BucketClass of the claim"
+// +kubebuilder:printcolumn:name="Bucket Name",type="string",JSONPath=".status.bucketName",description="The name of the bound S3 bucket"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The binding phase of the claim"
+
+// S3BucketClaim is the Schema for the s3bucketclaims API.
+type S3BucketClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S3BucketClaimSpec   `json:"spec,omitempty"`
+	Status S3BucketClaimStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3BucketClaimList contains a list of S3BucketClaim.
+type S3BucketClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3BucketClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3BucketClaim{}, &S3BucketClaimList{})
+}
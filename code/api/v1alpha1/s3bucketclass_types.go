@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reclaim policies decide what happens to a bucket when its S3BucketClaim is deleted.
+const (
+	// ReclaimPolicyDelete deletes the S3Bucket, and so the bucket, together with the claim.
+	ReclaimPolicyDelete = "Delete"
+	// ReclaimPolicyRetain keeps the S3Bucket and the bucket after the claim is deleted.
+	ReclaimPolicyRetain = "Retain"
+)
+
+// DefaultBucketClassAnnotation marks the S3BucketClass used by claims that do not name a class.
+const DefaultBucketClassAnnotation = "s3bucketclass.s3.acme.io/is-default-class"
+
+// S3BucketClassSpec defines the bucket defaults and the overrides claims of this class may use.
+type S3BucketClassSpec struct {
+	// Defaults is the S3Bucket spec stamped out for every claim of this class when its bucket is
+	// created. Later changes only apply to new claims. The bucket name is always generated per claim
+	// +optional
+	Defaults S3BucketSpec `json:"defaults,omitempty"`
+
+	// AllowedOverrides lists the S3Bucket spec fields, by JSON name, that claims may override, e.g. website
+	// +optional
+	AllowedOverrides []string `json:"allowedOverrides,omitempty"`
+
+	// BucketNamePrefix is prepended to the bucket names generated for claims
+	// +optional
+	BucketNamePrefix string `json:"bucketNamePrefix,omitempty"`
+
+	// ReclaimPolicy decides whether the bucket is deleted or retained when its claim is deleted
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Region",type="string",JSONPath=".spec.defaults.region",description="The default region of the buckets"
+// +kubebuilder:printcolumn:name="Reclaim Policy",type="string",JSONPath=".spec.reclaimPolicy",description="What happens to buckets when their claim is deleted"
+
+// S3BucketClass is the Schema for the s3bucketclasses API.
+type S3BucketClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec S3BucketClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3BucketClassList contains a list of S3BucketClass.
+type S3BucketClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3BucketClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3BucketClass{}, &S3BucketClassList{})
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultProviderConfigAnnotation marks the S3ProviderConfig used by buckets that do not reference one.
+const DefaultProviderConfigAnnotation = "s3providerconfig.s3.acme.io/is-default-config"
+
+// Namespace annotations selecting the IAM role assumed for the buckets of the namespace.
+// They take precedence over the roleArn and externalId of the provider config.
+const (
+	RoleARNAnnotation    = "s3.acme.io/role-arn"
+	ExternalIDAnnotation = "s3.acme.io/external-id"
+)
+
+// Storage providers an S3ProviderConfig can point at.
+const (
+	ProviderAWS   = "AWS"
+	ProviderGCS   = "GCS"
+	ProviderAzure = "Azure"
+	ProviderLocal = "Local"
+)
+
+// Keys read from the credentials Secret of an S3ProviderConfig.
+const (
+	CredentialsAccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	CredentialsSecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	CredentialsSessionTokenKey    = "AWS_SESSION_TOKEN"
+	// GCSCredentialsKey holds a Google service account key in JSON
+	GCSCredentialsKey = "credentials.json"
+	// AzureStorageKeyKey holds the shared key of the Azure storage account
+	AzureStorageKeyKey = "AZURE_STORAGE_KEY"
+	// AzureTenantIDKey, AzureClientIDKey and AzureClientSecretKey hold the service principal
+	// used with Azure Resource Manager
+	AzureTenantIDKey     = "AZURE_TENANT_ID"
+	AzureClientIDKey     = "AZURE_CLIENT_ID"
+	AzureClientSecretKey = "AZURE_CLIENT_SECRET"
+)
+
+// SecretReference references a Secret in a given namespace.
+type SecretReference struct {
+	// Name of the Secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// GCSProviderSpec configures Google Cloud Storage.
+type GCSProviderSpec struct {
+	// Project is the Google Cloud project new buckets are created in
+	// +kubebuilder:validation:MinLength=1
+	Project string `json:"project"`
+
+	// UniformBucketLevelAccess grants access to the objects of new buckets through IAM only,
+	// disabling object ACLs. Set it to false to keep fine-grained ACLs. Existing buckets keep
+	// their access control unless their drift policy is Enforce
+	// +kubebuilder:default=true
+	// +optional
+	UniformBucketLevelAccess *bool `json:"uniformBucketLevelAccess,omitempty"`
+}
+
+// AzureProviderSpec configures Azure Blob Storage. Buckets are containers of one storage account.
+// +kubebuilder:validation:XValidation:rule="has(self.subscriptionId) == has(self.resourceGroup)",message="subscriptionId and resourceGroup must be set together"
+type AzureProviderSpec struct {
+	// StorageAccount is the storage account the containers are created in
+	// +kubebuilder:validation:MinLength=3
+	StorageAccount string `json:"storageAccount"`
+
+	// SubscriptionID and ResourceGroup locate the storage account in Azure Resource Manager.
+	// They enable immutability policies and lifecycle management, using the service principal
+	// of the credentials Secret. Blob versioning applies to the whole storage account, so it is
+	// never changed; buckets requesting it report it unsupported unless the account has it enabled
+	// +optional
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+
+	// ResourceGroup is the resource group of the storage account
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+
+	// ManagementEndpoint is the Azure Resource Manager endpoint, e.g. for sovereign clouds
+	// +kubebuilder:default="https://management.azure.com"
+	// +optional
+	ManagementEndpoint string `json:"managementEndpoint,omitempty"`
+
+	// AuthorityHost is the Microsoft Entra ID endpoint issuing Azure Resource Manager tokens
+	// +kubebuilder:default="https://login.microsoftonline.com"
+	// +optional
+	AuthorityHost string `json:"authorityHost,omitempty"`
+}
+
+// LocalProviderSpec configures buckets stored as directories of the operator's filesystem,
+// for development clusters without cloud access.
+type LocalProviderSpec struct {
+	// Root is the directory of the operator container the buckets are created in. Mount a
+	// PersistentVolumeClaim there to keep the buckets across restarts
+	// +kubebuilder:validation:Pattern=`^/`
+	Root string `json:"root"`
+}
+
+// S3ProviderConfigSpec defines how the operator connects to an S3 account.
+// +kubebuilder:validation:XValidation:rule="self.type != 'GCS' || has(self.gcs)",message="gcs is required for the GCS provider"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Azure' || has(self.azure)",message="azure is required for the Azure provider"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Local' || has(self.local)",message="local is required for the Local provider"
+type S3ProviderConfigSpec struct {
+	// Type is the storage provider. Buckets of providers other than AWS are managed
+	// through the same S3Bucket resources, with unsupported fields reported in the
+	// FeaturesSupported condition
+	// +kubebuilder:validation:Enum=AWS;GCS;Azure;Local
+	// +kubebuilder:default=AWS
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// GCS configures Google Cloud Storage, for the GCS type
+	// +optional
+	GCS *GCSProviderSpec `json:"gcs,omitempty"`
+
+	// Azure configures Azure Blob Storage, for the Azure type
+	// +optional
+	Azure *AzureProviderSpec `json:"azure,omitempty"`
+
+	// Local configures buckets stored on the operator's filesystem, for the Local type
+	// +optional
+	Local *LocalProviderSpec `json:"local,omitempty"`
+
+	// Endpoint overrides the S3 endpoint URL, e.g. for S3-compatible storage such as MinIO or Ceph.
+	// For GCS it overrides the JSON API endpoint, for Azure the blob endpoint of the storage account,
+	// e.g. http://azurite:10000/devstoreaccount1. For Local it is the S3-compatible endpoint served
+	// by the operator, published to applications
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ForcePathStyle addresses buckets as <endpoint>/<bucket> instead of <bucket>.<endpoint>,
+	// as most S3-compatible storage requires
+	// +optional
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+
+	// CABundle is a PEM encoded CA bundle trusted in addition to the system roots
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// InsecureSkipVerify disables TLS certificate verification. Only meant for lab environments
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// UseDualStack uses the IPv4/IPv6 dual-stack endpoints of AWS
+	// +optional
+	UseDualStack bool `json:"useDualStack,omitempty"`
+
+	// UseFIPS uses the FIPS 140-2 validated endpoints of AWS
+	// +optional
+	UseFIPS bool `json:"useFIPS,omitempty"`
+
+	// Region is the region used for buckets that do not set one
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretRef references a Secret holding AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+	// and optionally AWS_SESSION_TOKEN, credentials.json for GCS, or AZURE_STORAGE_KEY and optionally
+	// AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET for Azure.
+	// The operator's own credentials are used when it is not set
+	// +optional
+	CredentialsSecretRef *SecretReference `json:"credentialsSecretRef,omitempty"`
+
+	// RoleARN is an IAM role assumed through STS before touching buckets, e.g. in another account
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
+	// +optional
+	RoleARN string `json:"roleArn,omitempty"`
+
+	// ExternalID is passed when assuming RoleARN, as required by the trust policy of the role
+	// +optional
+	ExternalID string `json:"externalId,omitempty"`
+}
+
+// S3ProviderConfigStatus defines the observed state of S3ProviderConfig.
+type S3ProviderConfigStatus struct {
+	// CredentialsVersion is a digest of the credentials held by the Secret last observed
+	// +optional
+	CredentialsVersion string `json:"credentialsVersion,omitempty"`
+
+	// LastRotationTime is when a change of the credentials Secret was last observed
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type",description="The storage provider"
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.endpoint",description="The S3 endpoint"
+// +kubebuilder:printcolumn:name="Region",type="string",JSONPath=".spec.region",description="The default region"
+// +kubebuilder:printcolumn:name="Role",type="string",JSONPath=".spec.roleArn",description="The IAM role assumed",priority=1
+// +kubebuilder:printcolumn:name="Last Rotation",type="date",JSONPath=".status.lastRotationTime",description="When the credentials last rotated"
+
+// S3ProviderConfig is the Schema for the s3providerconfigs API.
+type S3ProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S3ProviderConfigSpec   `json:"spec,omitempty"`
+	Status S3ProviderConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3ProviderConfigList contains a list of S3ProviderConfig.
+type S3ProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3ProviderConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3ProviderConfig{}, &S3ProviderConfigList{})
+}
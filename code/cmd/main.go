@@ -17,20 +17,21 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws" // AWS SDK for Go
-	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws"         // AWS SDK for Go
 	"github.com/aws/aws-sdk-go/aws/session" // AWS SDK session package
-	"github.com/aws/aws-sdk-go/service/s3"  // S3 service client
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -44,6 +45,12 @@ import (
 
 	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
 	"github.com/victorbecerragit/kube-s3-operator/code/internal/controller"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/metrics"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/local"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/tracing"
+	webhookv1 "github.com/victorbecerragit/kube-s3-operator/code/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -68,6 +75,14 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var awsCredentialsFile string
+	var localS3Addr, localS3Root string
+	var enablePodInjection bool
+	var usageInterval time.Duration
+	var driftInterval time.Duration
+	var usageMaxObjects int64
+	var tracingOpts tracing.Options
+	var providerCheckTTL time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -86,6 +101,31 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&awsCredentialsFile, "aws-credentials-file", os.Getenv("AWS_SHARED_CREDENTIALS_FILE"),
+		"The mounted AWS credentials file to watch; rotated credentials are picked up without a restart.")
+	flag.StringVar(&localS3Addr, "local-s3-bind-address", "0", "The address the S3-compatible endpoint of Local "+
+		"provider buckets binds to, e.g. :9000. Leave as 0 to disable it; it is unauthenticated and meant for development.")
+	flag.StringVar(&localS3Root, "local-s3-root", "/var/lib/kube-s3-operator/buckets",
+		"The directory the S3-compatible endpoint serves buckets from, the root of the Local provider configs.")
+	flag.DurationVar(&usageInterval, "bucket-usage-interval", time.Hour,
+		"How often the size and object count of each bucket are collected. 0 disables collection.")
+	flag.Int64Var(&usageMaxObjects, "bucket-usage-max-objects", 100000,
+		"The maximum number of objects counted when a bucket is listed to measure its usage. 0 counts every object.")
+	flag.DurationVar(&driftInterval, "drift-check-interval", 15*time.Minute,
+		"How often created buckets are checked for inventory, analytics and intelligent-tiering configurations "+
+			"added outside the operator. 0 only checks them when the spec changes.")
+	flag.StringVar(&tracingOpts.Endpoint, "tracing-endpoint", "", "The host:port of the OTLP gRPC collector "+
+		"the traces of reconciles are exported to. Leave empty to disable tracing.")
+	flag.BoolVar(&tracingOpts.Insecure, "tracing-insecure", false,
+		"If set, traces are exported to the collector without TLS.")
+	flag.Float64Var(&tracingOpts.SampleRatio, "tracing-sample-ratio", 1,
+		"The fraction of reconciles that are traced, between 0 and 1.")
+	flag.DurationVar(&providerCheckTTL, "provider-check-ttl", time.Minute,
+		"How long the readiness check of the object stores of every provider config is cached. 0 disables the check.")
+	flag.BoolVar(&enablePodInjection, "enable-pod-injection", false,
+		"If set, the mutating Pod webhook injects the connection details of the S3Buckets listed in the "+
+			"s3.acme.io/inject annotation of Pods labeled s3.acme.io/inject-enabled=true. "+
+			"Requires the webhook server certificates, see config/default.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -208,46 +248,107 @@ func main() {
 	}
 
 	// Setup the S3Bucket controller with the manager
-	// define AWS credentials and region from environment variables
+	// Operator-wide AWS credentials come from the standard provider chain: environment variables,
+	// web identity token file, shared config profile, container credentials endpoint or IMDS.
+	// They are optional: buckets can reference an S3ProviderConfig with its own credentials instead
+	sess, source, err := s3client.NewDefaultSession("us-west-2")
+	if err != nil {
+		setupLog.Info("No operator AWS credentials, buckets need an S3ProviderConfig with credentials", "reason", err.Error())
+	} else {
+		setupLog.Info("Using operator AWS credentials", "source", source, "region", aws.StringValue(sess.Config.Region))
+	}
 
-	id, ok := os.LookupEnv("AWS_ACCESS_KEY_ID")
-	if !ok {
-		setupLog.Error(err, "AWS_ACCESS_KEY_ID environment variable not set") // log the error and exit
-		os.Exit(1)                                                            // exit if the environment variable is not set, as we cannot proceed without it
+	// AWS clients are built per S3ProviderConfig and shared by the reconcilers
+	awsClients := s3client.NewCache(mgr.GetClient(), sess)
+
+	// Reload the operator credentials when the mounted credentials file rotates
+	if awsCredentialsFile != "" {
+		watcher := &s3client.CredentialsFileWatcher{
+			Path:  awsCredentialsFile,
+			Cache: awsClients,
+			NewSession: func() (*session.Session, string, error) {
+				return s3client.NewDefaultSession("us-west-2")
+			},
+			Recorder: mgr.GetEventRecorderFor("kube-s3-operator"),
+		}
+		// Rotation events are attached to the operator Pod when it knows its name
+		if podName, podNamespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE"); podName != "" && podNamespace != "" {
+			watcher.EventTarget = &corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: podName, Namespace: podNamespace}
+		}
+		if err := mgr.Add(watcher); err != nil {
+			setupLog.Error(err, "unable to add AWS credentials file watcher to manager")
+			os.Exit(1)
+		}
 	}
 
-	secret, ok := os.LookupEnv("AWS_SECRET_ACCESS_KEY")
-	if !ok {
-		setupLog.Error(err, "AWS_SECRET_ACCESS_KEY environment variable not set") // log the error and exit
-		os.Exit(1)                                                                // exit if the environment variable is not set, as we cannot proceed without it
+	// Report the S3Buckets per state and condition on the metrics endpoint
+	if err := metrics.RegisterBucketCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register S3Bucket metrics")
+		os.Exit(1)
 	}
 
-	region, ok := os.LookupEnv("AWS_REGION")
-	if !ok {
-		setupLog.Error(err, "AWS_REGION environment variable not set, using default us-west-2")
-		region = "us-west-2" // default region
+	// Export the traces of reconciles, S3 calls and Kubernetes writes
+	if tracingOpts.Endpoint != "" {
+		provider, err := tracing.Setup(context.Background(), tracingOpts)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		if err := mgr.Add(provider); err != nil {
+			setupLog.Error(err, "unable to add trace provider to manager")
+			os.Exit(1)
+		}
 	}
 
-	// Initialize AWS S3 client
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(region),
-		Credentials: credentials.NewStaticCredentials(id, secret, ""), // no token for now
-	})
-	if err != nil {
-		setupLog.Error(err, "unable to create AWS session")
-		os.Exit(1)
+	// Serve the buckets of Local provider configs to applications
+	if localS3Addr != "0" {
+		if err := mgr.Add(&local.Server{Addr: localS3Addr, Root: localS3Root}); err != nil {
+			setupLog.Error(err, "unable to add local S3 endpoint to manager")
+			os.Exit(1)
+		}
 	}
-	s3Client := s3.New(sess)
 
-	// Pass the s3Client to the reconciler
 	if err = (&controller.S3BucketReconciler{
+		Client:          tracing.WrapClient(mgr.GetClient()),
+		Scheme:          mgr.GetScheme(),
+		Clients:         awsClients,
+		DriftInterval:   driftInterval,
+		UsageInterval:   usageInterval,
+		UsageMaxObjects: usageMaxObjects,
+		Recorder:        recorder.New(mgr.GetEventRecorderFor("s3bucket-controller")),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "S3Bucket")
+		os.Exit(1)
+	}
+	if err = (&controller.S3BucketAccessReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Clients: awsClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "S3BucketAccess")
+		os.Exit(1)
+	}
+	if err = (&controller.S3ProviderConfigReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("s3providerconfig-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "S3ProviderConfig")
+		os.Exit(1)
+	}
+	if err = (&controller.S3BucketClaimReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-		S3svc:  s3Client,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "S3Bucket")
+		setupLog.Error(err, "unable to create controller", "controller", "S3BucketClaim")
 		os.Exit(1)
 	}
+	if enablePodInjection {
+		if err = webhookv1.SetupPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -274,6 +375,15 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Report not ready while a provider is unreachable or rejects its credentials; the failing
+	// providers are listed on /readyz/object-stores
+	if providerCheckTTL > 0 {
+		checker := s3client.NewReadinessChecker(awsClients, providerCheckTTL)
+		if err := mgr.AddReadyzCheck("object-stores", checker.Check); err != nil {
+			setupLog.Error(err, "unable to set up object store ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
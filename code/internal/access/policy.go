@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package access provisions IAM identities with least-privilege access to a single bucket.
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Access levels that can be granted on a bucket.
+const (
+	LevelRead  = "read"
+	LevelWrite = "write"
+	LevelAdmin = "admin"
+)
+
+// policyDocument is an IAM policy document
+type policyDocument struct {
+	Version   string      `json:"Version"`
+	Statement []statement `json:"Statement"`
+}
+
+// statement is a single statement of an IAM policy document
+type statement struct {
+	Sid       string                         `json:"Sid,omitempty"`
+	Effect    string                         `json:"Effect"`
+	Action    []string                       `json:"Action"`
+	Resource  []string                       `json:"Resource"`
+	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+var (
+	readBucketActions = []string{"s3:GetBucketLocation"}
+	readObjectActions = []string{"s3:GetObject", "s3:GetObjectVersion"}
+
+	writeBucketActions = append([]string{"s3:ListBucketMultipartUploads"}, readBucketActions...)
+	writeObjectActions = append([]string{
+		"s3:PutObject",
+		"s3:DeleteObject",
+		"s3:DeleteObjectVersion",
+		"s3:AbortMultipartUpload",
+		"s3:ListMultipartUploadParts",
+	}, readObjectActions...)
+)
+
+// BucketPolicy returns an IAM policy document granting the given access level on a single bucket.
+// A non-empty prefix restricts object access and listing to keys under that prefix.
+func BucketPolicy(bucket, level, prefix string) (string, error) {
+	bucketARN := "arn:aws:s3:::" + bucket
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	var bucketActions, objectActions []string
+	switch level {
+	case LevelRead:
+		bucketActions, objectActions = readBucketActions, readObjectActions
+	case LevelWrite:
+		bucketActions, objectActions = writeBucketActions, writeObjectActions
+	case LevelAdmin:
+		if prefix == "" {
+			bucketActions, objectActions = []string{"s3:*"}, []string{"s3:*"}
+		} else {
+			// Bucket-level administration cannot be limited to a prefix
+			bucketActions, objectActions = writeBucketActions, []string{"s3:*"}
+		}
+	default:
+		return "", fmt.Errorf("unknown access level %q", level)
+	}
+
+	statements := []statement{
+		{
+			Sid:      "Bucket",
+			Effect:   "Allow",
+			Action:   bucketActions,
+			Resource: []string{bucketARN},
+		},
+		{
+			Sid:      "List",
+			Effect:   "Allow",
+			Action:   []string{"s3:ListBucket"},
+			Resource: []string{bucketARN},
+		},
+		{
+			Sid:      "Objects",
+			Effect:   "Allow",
+			Action:   objectActions,
+			Resource: []string{bucketARN + "/" + prefix + "*"},
+		},
+	}
+	if prefix != "" {
+		statements[1].Condition = map[string]map[string][]string{
+			"StringLike": {"s3:prefix": {prefix + "*"}},
+		}
+	}
+
+	policy, err := json.Marshal(policyDocument{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal bucket policy: %w", err)
+	}
+
+	return string(policy), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BucketPolicy", func() {
+	parse := func(policy string) policyDocument {
+		document := policyDocument{}
+		Expect(json.Unmarshal([]byte(policy), &document)).To(Succeed())
+		return document
+	}
+
+	It("should grant read-only object access on the bucket", func() {
+		policy, err := BucketPolicy("reports", LevelRead, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		document := parse(policy)
+		Expect(document.Statement).To(HaveLen(3))
+		Expect(document.Statement[2].Resource).To(ConsistOf("arn:aws:s3:::reports/*"))
+		Expect(document.Statement[2].Action).To(ConsistOf("s3:GetObject", "s3:GetObjectVersion"))
+		Expect(document.Statement[1].Condition).To(BeNil())
+	})
+
+	It("should scope objects and listing to a prefix", func() {
+		policy, err := BucketPolicy("reports", LevelWrite, "/team-a/")
+		Expect(err).NotTo(HaveOccurred())
+
+		document := parse(policy)
+		Expect(document.Statement[1].Condition).To(HaveKeyWithValue("StringLike",
+			HaveKeyWithValue("s3:prefix", ConsistOf("team-a/*"))))
+		Expect(document.Statement[2].Resource).To(ConsistOf("arn:aws:s3:::reports/team-a/*"))
+		Expect(document.Statement[2].Action).To(ContainElement("s3:PutObject"))
+	})
+
+	It("should not grant bucket administration when scoped to a prefix", func() {
+		policy, err := BucketPolicy("reports", LevelAdmin, "team-a/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parse(policy).Statement[0].Action).NotTo(ContainElement("s3:*"))
+
+		policy, err = BucketPolicy("reports", LevelAdmin, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parse(policy).Statement[0].Action).To(ConsistOf("s3:*"))
+	})
+
+	It("should reject unknown access levels", func() {
+		_, err := BucketPolicy("reports", "owner", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,269 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+const (
+	// identityPath groups every IAM identity created by the operator
+	identityPath = "/kube-s3-operator/"
+	// inlinePolicyName is the name of the inline policy attached to provisioned identities
+	inlinePolicyName = "bucket-access"
+	// maxIdentityNameLength is the IAM limit for user and role names
+	maxIdentityNameLength = 64
+)
+
+// Identity describes an IAM user or role to provision.
+type Identity struct {
+	// Name is the IAM user or role name
+	Name string
+	// Policy is the inline policy document attached to the identity
+	Policy string
+	// TrustPolicy is the assume-role policy document of a role
+	TrustPolicy string
+	// AccessKeyID is the access key already handed out for a user, if any
+	AccessKeyID string
+	// Tags are attached to the identity when it is created
+	Tags map[string]string
+}
+
+// Credentials describe a provisioned identity.
+type Credentials struct {
+	// ARN is the ARN of the IAM user or role
+	ARN string
+	// AccessKeyID is the active access key of a user
+	AccessKeyID string
+	// SecretAccessKey is only set when a new access key was created
+	SecretAccessKey string
+}
+
+// Provisioner manages IAM identities scoped to a single bucket.
+type Provisioner struct {
+	iam iamiface.IAMAPI
+}
+
+// NewProvisioner returns a Provisioner using the given IAM client.
+func NewProvisioner(client iamiface.IAMAPI) *Provisioner {
+	return &Provisioner{iam: client}
+}
+
+// IdentityName returns an IAM-compliant identity name for a namespaced Kubernetes resource.
+// Names longer than the IAM limit are truncated and suffixed with a hash to stay unique.
+func IdentityName(prefix, namespace, name string) string {
+	identity := fmt.Sprintf("%s-%s-%s", prefix, namespace, name)
+	if len(identity) <= maxIdentityNameLength {
+		return identity
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return identity[:maxIdentityNameLength-9] + "-" + hex.EncodeToString(sum[:])[:8]
+}
+
+// EnsureUser creates the IAM user if needed, applies its inline policy and makes sure it has
+// exactly one usable access key. The secret access key is only returned when a new key was created.
+func (p *Provisioner) EnsureUser(ctx context.Context, identity Identity) (*Credentials, error) {
+	user, err := p.iam.GetUserWithContext(ctx, &iam.GetUserInput{UserName: aws.String(identity.Name)})
+	if err != nil && !isNoSuchEntity(err) {
+		return nil, fmt.Errorf("IAM GetUser API call failed: %w", err)
+	}
+
+	var arn string
+	if err == nil {
+		arn = aws.StringValue(user.User.Arn)
+	} else {
+		created, err := p.iam.CreateUserWithContext(ctx, &iam.CreateUserInput{
+			UserName: aws.String(identity.Name),
+			Path:     aws.String(identityPath),
+			Tags:     iamTags(identity.Tags),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("IAM CreateUser API call failed: %w", err)
+		}
+		arn = aws.StringValue(created.User.Arn)
+	}
+
+	if _, err := p.iam.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{
+		UserName:       aws.String(identity.Name),
+		PolicyName:     aws.String(inlinePolicyName),
+		PolicyDocument: aws.String(identity.Policy),
+	}); err != nil {
+		return nil, fmt.Errorf("IAM PutUserPolicy API call failed: %w", err)
+	}
+
+	keys, err := p.iam.ListAccessKeysWithContext(ctx, &iam.ListAccessKeysInput{UserName: aws.String(identity.Name)})
+	if err != nil {
+		return nil, fmt.Errorf("IAM ListAccessKeys API call failed: %w", err)
+	}
+	for _, key := range keys.AccessKeyMetadata {
+		if identity.AccessKeyID != "" && aws.StringValue(key.AccessKeyId) == identity.AccessKeyID {
+			return &Credentials{ARN: arn, AccessKeyID: identity.AccessKeyID}, nil
+		}
+	}
+
+	// The handed out key is unknown or gone, so the secrets of the remaining keys are lost: replace them
+	for _, key := range keys.AccessKeyMetadata {
+		if _, err := p.iam.DeleteAccessKeyWithContext(ctx, &iam.DeleteAccessKeyInput{
+			UserName:    aws.String(identity.Name),
+			AccessKeyId: key.AccessKeyId,
+		}); err != nil && !isNoSuchEntity(err) {
+			return nil, fmt.Errorf("IAM DeleteAccessKey API call failed: %w", err)
+		}
+	}
+
+	key, err := p.iam.CreateAccessKeyWithContext(ctx, &iam.CreateAccessKeyInput{UserName: aws.String(identity.Name)})
+	if err != nil {
+		return nil, fmt.Errorf("IAM CreateAccessKey API call failed: %w", err)
+	}
+
+	return &Credentials{
+		ARN:             arn,
+		AccessKeyID:     aws.StringValue(key.AccessKey.AccessKeyId),
+		SecretAccessKey: aws.StringValue(key.AccessKey.SecretAccessKey),
+	}, nil
+}
+
+// EnsureRole creates the IAM role if needed and applies its trust and inline policies
+func (p *Provisioner) EnsureRole(ctx context.Context, identity Identity) (*Credentials, error) {
+	role, err := p.iam.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(identity.Name)})
+	if err != nil && !isNoSuchEntity(err) {
+		return nil, fmt.Errorf("IAM GetRole API call failed: %w", err)
+	}
+
+	var arn string
+	if err == nil {
+		arn = aws.StringValue(role.Role.Arn)
+		if _, err := p.iam.UpdateAssumeRolePolicyWithContext(ctx, &iam.UpdateAssumeRolePolicyInput{
+			RoleName:       aws.String(identity.Name),
+			PolicyDocument: aws.String(identity.TrustPolicy),
+		}); err != nil {
+			return nil, fmt.Errorf("IAM UpdateAssumeRolePolicy API call failed: %w", err)
+		}
+	} else {
+		created, err := p.iam.CreateRoleWithContext(ctx, &iam.CreateRoleInput{
+			RoleName:                 aws.String(identity.Name),
+			Path:                     aws.String(identityPath),
+			AssumeRolePolicyDocument: aws.String(identity.TrustPolicy),
+			Tags:                     iamTags(identity.Tags),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("IAM CreateRole API call failed: %w", err)
+		}
+		arn = aws.StringValue(created.Role.Arn)
+	}
+
+	if _, err := p.iam.PutRolePolicyWithContext(ctx, &iam.PutRolePolicyInput{
+		RoleName:       aws.String(identity.Name),
+		PolicyName:     aws.String(inlinePolicyName),
+		PolicyDocument: aws.String(identity.Policy),
+	}); err != nil {
+		return nil, fmt.Errorf("IAM PutRolePolicy API call failed: %w", err)
+	}
+
+	return &Credentials{ARN: arn}, nil
+}
+
+// UserExists reports whether the IAM user exists
+func (p *Provisioner) UserExists(ctx context.Context, name string) (bool, error) {
+	_, err := p.iam.GetUserWithContext(ctx, &iam.GetUserInput{UserName: aws.String(name)})
+	if isNoSuchEntity(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("IAM GetUser API call failed: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteUser removes the access keys, the inline policy and the IAM user itself.
+// Identities that are already gone are ignored.
+func (p *Provisioner) DeleteUser(ctx context.Context, name string) error {
+	keys, err := p.iam.ListAccessKeysWithContext(ctx, &iam.ListAccessKeysInput{UserName: aws.String(name)})
+	if err != nil {
+		if isNoSuchEntity(err) {
+			return nil
+		}
+		return fmt.Errorf("IAM ListAccessKeys API call failed: %w", err)
+	}
+	for _, key := range keys.AccessKeyMetadata {
+		if _, err := p.iam.DeleteAccessKeyWithContext(ctx, &iam.DeleteAccessKeyInput{
+			UserName:    aws.String(name),
+			AccessKeyId: key.AccessKeyId,
+		}); err != nil && !isNoSuchEntity(err) {
+			return fmt.Errorf("IAM DeleteAccessKey API call failed: %w", err)
+		}
+	}
+
+	if _, err := p.iam.DeleteUserPolicyWithContext(ctx, &iam.DeleteUserPolicyInput{
+		UserName:   aws.String(name),
+		PolicyName: aws.String(inlinePolicyName),
+	}); err != nil && !isNoSuchEntity(err) {
+		return fmt.Errorf("IAM DeleteUserPolicy API call failed: %w", err)
+	}
+
+	if _, err := p.iam.DeleteUserWithContext(ctx, &iam.DeleteUserInput{UserName: aws.String(name)}); err != nil && !isNoSuchEntity(err) {
+		return fmt.Errorf("IAM DeleteUser API call failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRole removes the inline policy and the IAM role itself. Roles that are already gone are ignored.
+func (p *Provisioner) DeleteRole(ctx context.Context, name string) error {
+	if _, err := p.iam.DeleteRolePolicyWithContext(ctx, &iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(name),
+		PolicyName: aws.String(inlinePolicyName),
+	}); err != nil && !isNoSuchEntity(err) {
+		return fmt.Errorf("IAM DeleteRolePolicy API call failed: %w", err)
+	}
+
+	if _, err := p.iam.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{RoleName: aws.String(name)}); err != nil && !isNoSuchEntity(err) {
+		return fmt.Errorf("IAM DeleteRole API call failed: %w", err)
+	}
+
+	return nil
+}
+
+// iamTags converts a tag map into sorted IAM tags
+func iamTags(tags map[string]string) []*iam.Tag {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*iam.Tag, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, &iam.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return result
+}
+
+// isNoSuchEntity reports whether err is the IAM error for a missing user, role, key or policy
+func isNoSuchEntity(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == iam.ErrCodeNoSuchEntityException
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeIAM keeps IAM users and their access keys in memory
+type fakeIAM struct {
+	iamiface.IAMAPI
+	users    map[string][]string
+	policies map[string]string
+	nextKey  int
+}
+
+func newFakeIAM() *fakeIAM {
+	return &fakeIAM{users: map[string][]string{}, policies: map[string]string{}}
+}
+
+func (f *fakeIAM) noSuchEntity() error {
+	return awserr.New(iam.ErrCodeNoSuchEntityException, "not found", nil)
+}
+
+func (f *fakeIAM) GetUserWithContext(_ aws.Context, in *iam.GetUserInput, _ ...request.Option) (*iam.GetUserOutput, error) {
+	if _, ok := f.users[*in.UserName]; !ok {
+		return nil, f.noSuchEntity()
+	}
+	return &iam.GetUserOutput{User: &iam.User{Arn: aws.String("arn:aws:iam::123456789012:user/" + *in.UserName)}}, nil
+}
+
+func (f *fakeIAM) CreateUserWithContext(_ aws.Context, in *iam.CreateUserInput, _ ...request.Option) (*iam.CreateUserOutput, error) {
+	f.users[*in.UserName] = nil
+	return &iam.CreateUserOutput{User: &iam.User{Arn: aws.String("arn:aws:iam::123456789012:user/" + *in.UserName)}}, nil
+}
+
+func (f *fakeIAM) PutUserPolicyWithContext(_ aws.Context, in *iam.PutUserPolicyInput, _ ...request.Option) (*iam.PutUserPolicyOutput, error) {
+	f.policies[*in.UserName] = *in.PolicyDocument
+	return &iam.PutUserPolicyOutput{}, nil
+}
+
+func (f *fakeIAM) DeleteUserPolicyWithContext(_ aws.Context, in *iam.DeleteUserPolicyInput, _ ...request.Option) (*iam.DeleteUserPolicyOutput, error) {
+	if _, ok := f.policies[*in.UserName]; !ok {
+		return nil, f.noSuchEntity()
+	}
+	delete(f.policies, *in.UserName)
+	return &iam.DeleteUserPolicyOutput{}, nil
+}
+
+func (f *fakeIAM) ListAccessKeysWithContext(_ aws.Context, in *iam.ListAccessKeysInput, _ ...request.Option) (*iam.ListAccessKeysOutput, error) {
+	keys, ok := f.users[*in.UserName]
+	if !ok {
+		return nil, f.noSuchEntity()
+	}
+	output := &iam.ListAccessKeysOutput{}
+	for _, key := range keys {
+		output.AccessKeyMetadata = append(output.AccessKeyMetadata, &iam.AccessKeyMetadata{AccessKeyId: aws.String(key)})
+	}
+	return output, nil
+}
+
+func (f *fakeIAM) CreateAccessKeyWithContext(_ aws.Context, in *iam.CreateAccessKeyInput, _ ...request.Option) (*iam.CreateAccessKeyOutput, error) {
+	f.nextKey++
+	key := "AKIA" + strings.Repeat("0", f.nextKey)
+	f.users[*in.UserName] = append(f.users[*in.UserName], key)
+	return &iam.CreateAccessKeyOutput{AccessKey: &iam.AccessKey{AccessKeyId: aws.String(key), SecretAccessKey: aws.String("secret-" + key)}}, nil
+}
+
+func (f *fakeIAM) DeleteAccessKeyWithContext(_ aws.Context, in *iam.DeleteAccessKeyInput, _ ...request.Option) (*iam.DeleteAccessKeyOutput, error) {
+	var remaining []string
+	for _, key := range f.users[*in.UserName] {
+		if key != *in.AccessKeyId {
+			remaining = append(remaining, key)
+		}
+	}
+	f.users[*in.UserName] = remaining
+	return &iam.DeleteAccessKeyOutput{}, nil
+}
+
+func (f *fakeIAM) DeleteUserWithContext(_ aws.Context, in *iam.DeleteUserInput, _ ...request.Option) (*iam.DeleteUserOutput, error) {
+	if _, ok := f.users[*in.UserName]; !ok {
+		return nil, f.noSuchEntity()
+	}
+	delete(f.users, *in.UserName)
+	return &iam.DeleteUserOutput{}, nil
+}
+
+var _ = Describe("Provisioner", func() {
+	var (
+		ctx         context.Context
+		fake        *fakeIAM
+		provisioner *Provisioner
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = newFakeIAM()
+		provisioner = NewProvisioner(fake)
+	})
+
+	It("should create a user with a single access key and reuse it afterwards", func() {
+		identity := Identity{Name: "s3bucket-default-reports", Policy: "{}"}
+
+		created, err := provisioner.EnsureUser(ctx, identity)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.ARN).To(HaveSuffix("user/s3bucket-default-reports"))
+		Expect(created.SecretAccessKey).NotTo(BeEmpty())
+
+		identity.AccessKeyID = created.AccessKeyID
+		reused, err := provisioner.EnsureUser(ctx, identity)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reused.AccessKeyID).To(Equal(created.AccessKeyID))
+		Expect(reused.SecretAccessKey).To(BeEmpty())
+		Expect(fake.users[identity.Name]).To(HaveLen(1))
+	})
+
+	It("should replace access keys whose secret was lost", func() {
+		identity := Identity{Name: "s3bucket-default-reports", Policy: "{}"}
+		first, err := provisioner.EnsureUser(ctx, identity)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := provisioner.EnsureUser(ctx, identity)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.AccessKeyID).NotTo(Equal(first.AccessKeyID))
+		Expect(fake.users[identity.Name]).To(ConsistOf(second.AccessKeyID))
+	})
+
+	It("should delete users and ignore users that are already gone", func() {
+		_, err := provisioner.EnsureUser(ctx, Identity{Name: "s3bucket-default-reports", Policy: "{}"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(provisioner.UserExists(ctx, "s3bucket-default-reports")).To(BeTrue())
+
+		Expect(provisioner.DeleteUser(ctx, "s3bucket-default-reports")).To(Succeed())
+		Expect(fake.users).To(BeEmpty())
+		Expect(provisioner.DeleteUser(ctx, "s3bucket-default-reports")).To(Succeed())
+		Expect(provisioner.UserExists(ctx, "s3bucket-default-reports")).To(BeFalse())
+	})
+
+	It("should keep identity names within the IAM length limit", func() {
+		Expect(IdentityName("s3bucket", "default", "reports")).To(Equal("s3bucket-default-reports"))
+
+		long := IdentityName("s3bucket", strings.Repeat("n", 40), strings.Repeat("b", 40))
+		Expect(long).To(HaveLen(64))
+		Expect(long).NotTo(Equal(IdentityName("s3bucket", strings.Repeat("n", 40), strings.Repeat("c", 40))))
+	})
+})
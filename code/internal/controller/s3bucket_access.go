@@ -0,0 +1,237 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/access"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	credentialsSecretName = "%s-s3-credentials"
+	// bucketIdentityPrefix prefixes the IAM identities provisioned for spec.access
+	bucketIdentityPrefix = "s3bucket"
+
+	// Keys of the credentials Secret
+	bucketNameKey      = "BUCKET_NAME"
+	regionKey          = "AWS_REGION"
+	accessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	secretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	roleARNKey         = "AWS_ROLE_ARN"
+	bucketPrefixKey    = "BUCKET_PREFIX"
+)
+
+// accessIdentity returns the type and name of the IAM identity provisioned for the bucket,
+// preferring what was recorded in status over the current spec
+func accessIdentity(s3bkt *s3v1alpha1.S3Bucket) (string, string) {
+	if s3bkt.Status.Access != nil {
+		return s3bkt.Status.Access.IdentityType, s3bkt.Status.Access.IdentityName
+	}
+	identityType := s3v1alpha1.IdentityTypeUser
+	if s3bkt.Spec.Access != nil && s3bkt.Spec.Access.IdentityType != "" {
+		identityType = s3bkt.Spec.Access.IdentityType
+	}
+	return identityType, access.IdentityName(bucketIdentityPrefix, s3bkt.Namespace, s3bkt.Name)
+}
+
+// reconcileAccess provisions, updates or removes the scoped IAM identity of the bucket
+// and records it in the in-memory status
+func (r *S3BucketReconciler) reconcileAccess(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	if s3bkt.Spec.Access == nil {
+		if s3bkt.Status.Access == nil {
+			return nil
+		}
+		if err := r.deleteAccess(ctx, s3bkt); err != nil {
+			return err
+		}
+		s3bkt.Status.Access = nil
+		return nil
+	}
+
+	identityType := s3bkt.Spec.Access.IdentityType
+	if identityType == "" {
+		identityType = s3v1alpha1.IdentityTypeUser
+	}
+	// Switching between a user and a role replaces the previous identity
+	if s3bkt.Status.Access != nil && s3bkt.Status.Access.IdentityType != identityType {
+		if err := r.deleteAccess(ctx, s3bkt); err != nil {
+			return err
+		}
+		s3bkt.Status.Access = nil
+	}
+
+	policy, err := access.BucketPolicy(s3bkt.Spec.Name, defaultString(s3bkt.Spec.Access.Level, access.LevelRead), "")
+	if err != nil {
+		return err
+	}
+
+	accessStatus, err := ensureAccessIdentity(ctx, r.Client, r.Scheme, access.NewProvisioner(r.IAMsvc), s3bkt, accessRequest{
+		identityType: identityType,
+		identityName: access.IdentityName(bucketIdentityPrefix, s3bkt.Namespace, s3bkt.Name),
+		policy:       policy,
+		trustPolicy:  s3bkt.Spec.Access.TrustPolicy,
+		secretName:   fmt.Sprintf(credentialsSecretName, s3bkt.Name),
+		data: map[string]string{
+			bucketNameKey: s3bkt.Spec.Name,
+			regionKey:     r.bucketRegion(s3bkt),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	s3bkt.Status.Access = accessStatus
+	return nil
+}
+
+// deleteAccess removes the scoped IAM identity of the bucket and its credentials Secret
+func (r *S3BucketReconciler) deleteAccess(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	identityType, identityName := accessIdentity(s3bkt)
+	return deleteAccessIdentity(ctx, r.Client, access.NewProvisioner(r.IAMsvc), identityType, identityName, types.NamespacedName{
+		Name:      fmt.Sprintf(credentialsSecretName, s3bkt.Name),
+		Namespace: s3bkt.Namespace,
+	})
+}
+
+// accessRequest describes an IAM identity and the Secret its credentials are written to
+type accessRequest struct {
+	identityType string
+	identityName string
+	policy       string
+	trustPolicy  string
+	secretName   string
+	// data holds additional non-sensitive keys written to the Secret
+	data map[string]string
+}
+
+// ensureAccessIdentity provisions the requested IAM identity and writes its credentials into
+// a Secret owned by owner. Existing access keys are reused as long as the Secret still holds them.
+func ensureAccessIdentity(ctx context.Context, c client.Client, scheme *runtime.Scheme, provisioner *access.Provisioner,
+	owner client.Object, request accessRequest) (*s3v1alpha1.AccessStatus, error) {
+	log := logf.FromContext(ctx)
+	log.Info("Ensuring scoped IAM identity", "IdentityType", request.identityType, "IdentityName", request.identityName)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      request.secretName,
+			Namespace: owner.GetNamespace(),
+		},
+	}
+
+	// Reuse the access key handed out previously, if the Secret still has it
+	var accessKeyID string
+	existing := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(secret), existing); err == nil {
+		accessKeyID = string(existing.Data[accessKeyIDKey])
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get credentials Secret: %w", err)
+	}
+
+	identity := access.Identity{
+		Name:        request.identityName,
+		Policy:      request.policy,
+		TrustPolicy: request.trustPolicy,
+		AccessKeyID: accessKeyID,
+		Tags: map[string]string{
+			"kube-s3-operator/namespace": owner.GetNamespace(),
+			"kube-s3-operator/name":      owner.GetName(),
+		},
+	}
+
+	var credentials *access.Credentials
+	var err error
+	if request.identityType == s3v1alpha1.IdentityTypeRole {
+		credentials, err = provisioner.EnsureRole(ctx, identity)
+	} else {
+		credentials, err = provisioner.EnsureUser(ctx, identity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision IAM %s: %w", request.identityType, err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for key, value := range request.data {
+			secret.Data[key] = []byte(value)
+		}
+		if request.identityType == s3v1alpha1.IdentityTypeRole {
+			secret.Data[roleARNKey] = []byte(credentials.ARN)
+			delete(secret.Data, accessKeyIDKey)
+			delete(secret.Data, secretAccessKeyKey)
+		} else {
+			secret.Data[accessKeyIDKey] = []byte(credentials.AccessKeyID)
+			if credentials.SecretAccessKey != "" {
+				secret.Data[secretAccessKeyKey] = []byte(credentials.SecretAccessKey)
+			}
+			delete(secret.Data, roleARNKey)
+		}
+		return controllerutil.SetControllerReference(owner, secret, scheme)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update credentials Secret: %w", err)
+	}
+
+	return &s3v1alpha1.AccessStatus{
+		IdentityType: request.identityType,
+		IdentityName: request.identityName,
+		IdentityARN:  credentials.ARN,
+		SecretName:   request.secretName,
+	}, nil
+}
+
+// deleteAccessIdentity removes an IAM identity and its credentials Secret. Missing ones are ignored.
+func deleteAccessIdentity(ctx context.Context, c client.Client, provisioner *access.Provisioner,
+	identityType, identityName string, secretName types.NamespacedName) error {
+	log := logf.FromContext(ctx)
+	log.Info("Deleting scoped IAM identity", "IdentityType", identityType, "IdentityName", identityName)
+
+	var err error
+	if identityType == s3v1alpha1.IdentityTypeRole {
+		err = provisioner.DeleteRole(ctx, identityName)
+	} else {
+		err = provisioner.DeleteUser(ctx, identityName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete IAM %s: %w", identityType, err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName.Name,
+			Namespace: secretName.Namespace,
+		},
+	}
+	if err := c.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete credentials Secret: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+const (
+	bindingSecretName = "%s-s3-binding"
+	// bindingType is the servicebinding.io type of buckets, also used for the Secret type
+	bindingType       = "s3"
+	bindingSecretType = corev1.SecretType("servicebinding.io/" + bindingType)
+)
+
+// ensureBindingSecret projects the bucket into a Secret following the servicebinding.io
+// specification and records it in the in-memory status, so the S3Bucket is a provisioned service
+func (r *S3BucketReconciler) ensureBindingSecret(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, facts connectionFacts) error {
+	credentials, err := r.bucketCredentials(ctx, s3bkt)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		"type":              bindingType,
+		"provider":          strings.ToLower(r.providerType()),
+		"bucket":            facts.BucketName,
+		"region":            facts.Region,
+		"endpoint":          facts.Endpoint,
+		"access-key-id":     credentials[accessKeyIDKey],
+		"secret-access-key": credentials[secretAccessKeyKey],
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(bindingSecretName, s3bkt.Name),
+			Namespace: s3bkt.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		// The type of existing Secrets is immutable; it is only set on creation
+		if secret.CreationTimestamp.IsZero() {
+			secret.Type = bindingSecretType
+		}
+		secret.Data = map[string][]byte{}
+		for key, value := range data {
+			if value != "" {
+				secret.Data[key] = []byte(value)
+			}
+		}
+		return controllerutil.SetControllerReference(s3bkt, secret, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to write binding Secret: %w", err)
+	}
+
+	s3bkt.Status.Binding = &s3v1alpha1.BindingStatus{Name: secret.Name}
+	return nil
+}
+
+// providerType returns the type of the provider config of the bucket being reconciled
+func (r *S3BucketReconciler) providerType() string {
+	if r.clients == nil || r.clients.Provider == "" {
+		return s3v1alpha1.ProviderAWS
+	}
+	return r.clients.Provider
+}
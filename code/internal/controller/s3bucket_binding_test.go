@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+)
+
+var _ = Describe("S3Bucket service binding", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		s3bkt  *s3v1alpha1.S3Bucket
+	)
+
+	getBindingSecret := func(c client.Client) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-binding", Namespace: "default"}, secret)).To(Succeed())
+		return secret
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: "my-data-bucket"},
+		}
+	})
+
+	It("should project an AWS bucket and its scoped credentials", func() {
+		credentialsSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-s3-credentials", Namespace: "default"},
+			Data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("AKIAEXAMPLE"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt, credentialsSecret).Build()
+		sess, err := session.NewSession(&aws.Config{
+			Region:      aws.String("eu-west-1"),
+			Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		reconciler := &S3BucketReconciler{Client: c, Scheme: scheme, S3svc: s3.New(sess)}
+
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		secret := getBindingSecret(c)
+		Expect(secret.Type).To(Equal(corev1.SecretType("servicebinding.io/s3")))
+		Expect(secret.Data).To(Equal(map[string][]byte{
+			"type":              []byte("s3"),
+			"provider":          []byte("aws"),
+			"bucket":            []byte("my-data-bucket"),
+			"region":            []byte("eu-west-1"),
+			"endpoint":          []byte("https://s3.eu-west-1.amazonaws.com"),
+			"access-key-id":     []byte("AKIAEXAMPLE"),
+			"secret-access-key": []byte("secret"),
+		}))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(s3bkt.Status.Binding).To(Equal(&s3v1alpha1.BindingStatus{Name: "data-s3-binding"}))
+	})
+
+	It("should expose the binding Secret of buckets of other providers in the status", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+			WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+		reconciler := &S3BucketReconciler{
+			Client:  c,
+			Scheme:  scheme,
+			manager: &fakeBucketManager{buckets: map[string]bool{}},
+			clients: &s3client.Clients{Provider: s3v1alpha1.ProviderGCS},
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(s3bkt)}
+		for range 2 {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		latest := &s3v1alpha1.S3Bucket{}
+		Expect(c.Get(ctx, request.NamespacedName, latest)).To(Succeed())
+		Expect(latest.Status.Binding).NotTo(BeNil())
+		Expect(latest.Status.Binding.Name).To(Equal("data-s3-binding"))
+
+		secret := getBindingSecret(c)
+		Expect(secret.Data).To(HaveKeyWithValue("provider", []byte("gcs")))
+		Expect(secret.Data).To(HaveKeyWithValue("region", []byte("EUROPE-WEST1")))
+		Expect(secret.Data).NotTo(HaveKey("access-key-id"))
+	})
+})
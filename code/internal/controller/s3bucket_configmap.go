@@ -0,0 +1,164 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+// ensureBucketConfigMap creates the ConfigMap of an S3 bucket or repairs it after it was edited
+// or deleted. location is the Location returned by CreateBucket, only known right after creation
+func (r *S3BucketReconciler) ensureBucketConfigMap(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, location string) error {
+	region := r.bucketRegion(s3bkt)
+	data := map[string]string{
+		"BucketName": s3bkt.Spec.Name,
+		"Region":     region,
+		"Locked":     fmt.Sprintf("%t", s3bkt.Spec.Locked),
+		"ARN":        bucketARN(s3bkt.Spec.Name, region),
+		"Endpoint":   r.S3svc.Endpoint,
+		"URL":        r.bucketURL(s3bkt.Spec.Name),
+		"location":   location,
+	}
+	for key, value := range configurationData(s3bkt) {
+		data[key] = value
+	}
+	facts := connectionFacts{
+		BucketName: s3bkt.Spec.Name,
+		Region:     region,
+		Endpoint:   data["Endpoint"],
+		ARN:        data["ARN"],
+		URL:        data["URL"],
+	}
+	if err := r.ensureBindingSecret(ctx, s3bkt, facts); err != nil {
+		return err
+	}
+	details, err := r.publishConnectionDetails(ctx, s3bkt, facts)
+	if err != nil {
+		return err
+	}
+
+	// Keep the Location reported at creation; ConfigMaps recreated later get the bucket URL
+	var defaults map[string]string
+	if location == "" {
+		delete(data, "location")
+		defaults = map[string]string{"location": data["URL"]}
+	}
+	return r.writeBucketConfigMap(ctx, s3bkt, data, defaults, details)
+}
+
+// writeBucketConfigMap creates or updates the bucket ConfigMap with the given keys, removing keys
+// with empty values. Defaults are only set when the key is missing. Rendered connection details
+// replace the previously rendered ones; other keys are left alone
+func (r *S3BucketReconciler) writeBucketConfigMap(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, data, defaults, details map[string]string) error {
+	log := logf.FromContext(ctx)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(configMapName, s3bkt.Name),
+			Namespace: s3bkt.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		for key, value := range defaults {
+			if _, found := cm.Data[key]; !found && value != "" {
+				cm.Data[key] = value
+			}
+		}
+		for key, value := range data {
+			if value == "" {
+				delete(cm.Data, key)
+				continue
+			}
+			cm.Data[key] = value
+		}
+
+		if previous := cm.Annotations[connectionDetailsAnnotation]; previous != "" {
+			for _, key := range strings.Split(previous, ",") {
+				if _, found := data[key]; !found {
+					delete(cm.Data, key)
+				}
+			}
+		}
+		for key, value := range details {
+			cm.Data[key] = value
+		}
+		if len(details) == 0 {
+			delete(cm.Annotations, connectionDetailsAnnotation)
+		} else {
+			metav1.SetMetaDataAnnotation(&cm.ObjectMeta, connectionDetailsAnnotation, renderedKeys(details))
+		}
+		return controllerutil.SetControllerReference(s3bkt, cm, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap: %w", err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.Info("Bucket ConfigMap reconciled", "BucketName", s3bkt.Spec.Name, "Result", result)
+		// With the spec already applied, a changed ConfigMap was edited or deleted by someone else
+		if s3bkt.Status.State == s3v1alpha1.CREATED_STATE && s3bkt.Status.ObservedGeneration == s3bkt.Generation {
+			change := "edited"
+			if result == controllerutil.OperationResultCreated {
+				change = "deleted"
+			}
+			r.Recorder.Normal(s3bkt, recorder.ReasonDriftDetected, "ConfigMap %s was %s, restored it", cm.Name, change)
+		}
+	}
+	return nil
+}
+
+// repairBucketConfigMap restores the ConfigMap of a bucket whose spec did not change, without
+// changing the bucket itself
+func (r *S3BucketReconciler) repairBucketConfigMap(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	if r.manager != nil {
+		// The connection details come from the state last reported by the provider. The first
+		// repair after the operator started has none and ensures the bucket once
+		if state := r.states.load(s3bkt, r.manager); state != nil {
+			return r.publishManagedBucket(ctx, s3bkt, state)
+		}
+		return r.ensureManagedBucket(ctx, s3bkt)
+	}
+	return r.ensureBucketConfigMap(ctx, s3bkt, "")
+}
+
+// bucketURL returns the virtual-hosted-style URL of a bucket, or the path-style URL when the
+// provider config forces path-style addressing
+func (r *S3BucketReconciler) bucketURL(bucket string) string {
+	endpoint, err := url.Parse(r.S3svc.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return ""
+	}
+	if aws.BoolValue(r.S3svc.Config.S3ForcePathStyle) {
+		return strings.TrimSuffix(r.S3svc.Endpoint, "/") + "/" + bucket
+	}
+	endpoint.Host = bucket + "." + endpoint.Host
+	return endpoint.String()
+}
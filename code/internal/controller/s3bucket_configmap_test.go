@@ -0,0 +1,176 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+var _ = Describe("S3Bucket ConfigMap", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *S3BucketReconciler
+		s3bkt      *s3v1alpha1.S3Bucket
+		cmKey      client.ObjectKey
+	)
+
+	newS3Client := func(config *aws.Config) *s3.S3 {
+		sess, err := session.NewSession(config.
+			WithRegion("eu-west-1").
+			WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "")))
+		Expect(err).NotTo(HaveOccurred())
+		return s3.New(sess)
+	}
+
+	getConfigMap := func() *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, cmKey, cm)).To(Succeed())
+		return cm
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "data",
+				Namespace:  "default",
+				Finalizers: []string{s3BucketFinalizer},
+			},
+			Spec:   s3v1alpha1.S3BucketSpec{Name: "my-data-bucket"},
+			Status: s3v1alpha1.S3BucketStatus{State: s3v1alpha1.CREATED_STATE, RequestPayer: s3.PayerBucketOwner},
+		}
+		cmKey = client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+			WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+		reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, S3svc: newS3Client(&aws.Config{})}
+	})
+
+	It("should publish the ARN, endpoint, URL and effective region", func() {
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "http://my-data-bucket.s3.amazonaws.com/")).To(Succeed())
+
+		cm := getConfigMap()
+		Expect(cm.Data).To(Equal(map[string]string{
+			"BucketName":    "my-data-bucket",
+			"Region":        "eu-west-1",
+			"Locked":        "false",
+			"ARN":           "arn:aws:s3:::my-data-bucket",
+			"Endpoint":      "https://s3.eu-west-1.amazonaws.com",
+			"URL":           "https://my-data-bucket.s3.eu-west-1.amazonaws.com",
+			"location":      "http://my-data-bucket.s3.amazonaws.com/",
+			"RequesterPays": "false",
+		}))
+		Expect(cm.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should take over an existing ConfigMap instead of failing", func() {
+		Expect(c.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: cmKey.Name, Namespace: cmKey.Namespace},
+			Data:       map[string]string{"BucketName": "stale", "team": "analytics"},
+		})).To(Succeed())
+
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "/my-data-bucket")).To(Succeed())
+
+		cm := getConfigMap()
+		Expect(cm.Data).To(HaveKeyWithValue("BucketName", "my-data-bucket"))
+		Expect(cm.Data).To(HaveKeyWithValue("location", "/my-data-bucket"))
+		Expect(cm.Data).To(HaveKeyWithValue("team", "analytics"))
+		Expect(cm.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should repair an edited or deleted ConfigMap of a created bucket", func() {
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(s3bkt)}
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "/my-data-bucket")).To(Succeed())
+		events := record.NewFakeRecorder(10)
+		reconciler.Recorder = recorder.New(events)
+
+		By("restoring edited keys")
+		cm := getConfigMap()
+		cm.Data["Region"] = "us-east-1"
+		Expect(c.Update(ctx, cm)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getConfigMap().Data).To(HaveKeyWithValue("Region", "eu-west-1"))
+		Expect(getConfigMap().Data).To(HaveKeyWithValue("location", "/my-data-bucket"))
+		Expect(events.Events).To(Receive(Equal("Normal DriftDetected ConfigMap data-s3-cm was edited, restored it")))
+
+		By("recreating a deleted ConfigMap")
+		Expect(c.Delete(ctx, getConfigMap())).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getConfigMap().Data).To(HaveKeyWithValue("BucketName", "my-data-bucket"))
+		Expect(getConfigMap().Data).To(HaveKeyWithValue("location", "https://my-data-bucket.s3.eu-west-1.amazonaws.com"))
+		Expect(events.Events).To(Receive(Equal("Normal DriftDetected ConfigMap data-s3-cm was deleted, restored it")))
+
+		By("staying quiet while nothing drifted")
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events.Events).NotTo(Receive())
+	})
+
+	It("should publish path-style URLs of S3-compatible endpoints", func() {
+		reconciler.S3svc = newS3Client(&aws.Config{
+			Endpoint:         aws.String("https://minio.storage.svc:9000"),
+			S3ForcePathStyle: aws.Bool(true),
+		})
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		cm := getConfigMap()
+		Expect(cm.Data).To(HaveKeyWithValue("Endpoint", "https://minio.storage.svc:9000"))
+		Expect(cm.Data).To(HaveKeyWithValue("URL", "https://minio.storage.svc:9000/my-data-bucket"))
+	})
+
+	It("should create the bucket in the region it publishes", func() {
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			raw, _ := io.ReadAll(req.Body)
+			body = string(raw)
+		}))
+		DeferCleanup(server.Close)
+		reconciler.S3svc = newS3Client(&aws.Config{Endpoint: aws.String(server.URL), S3ForcePathStyle: aws.Bool(true)})
+
+		_, err := reconciler.createS3Bucket(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(ContainSubstring("<LocationConstraint>eu-west-1</LocationConstraint>"))
+		Expect(reconciler.bucketRegion(s3bkt)).To(Equal("eu-west-1"))
+	})
+})
@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+const (
+	connectionSecretName = "%s-s3-connection"
+	// connectionDetailsAnnotation lists the ConfigMap keys rendered from spec.connectionDetails,
+	// so the keys of removed entries are cleaned up
+	connectionDetailsAnnotation = "s3.acme.io/connection-details"
+)
+
+// connectionFacts are the bucket facts connection details templates are rendered over
+type connectionFacts struct {
+	BucketName string
+	Region     string
+	Endpoint   string
+	ARN        string
+	URL        string
+	// Credentials holds the keys of the credentials Secret, only for Secret targets
+	Credentials map[string]string
+}
+
+// connectionTemplateFuncs are the functions available to connection details templates
+var connectionTemplateFuncs = template.FuncMap{
+	"toJson": func(value any) (string, error) {
+		raw, err := json.Marshal(value)
+		return string(raw), err
+	},
+	"b64enc": func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// publishConnectionDetails renders spec.connectionDetails, writes the Secret targets to the
+// connection Secret and returns the ConfigMap targets. Template errors are reported in the
+// ConnectionDetailsRendered condition and their keys are left out
+func (r *S3BucketReconciler) publishConnectionDetails(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, facts connectionFacts) (map[string]string, error) {
+	if len(s3bkt.Spec.ConnectionDetails) == 0 {
+		meta.RemoveStatusCondition(&s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)
+		return nil, r.deleteConnectionSecret(ctx, s3bkt)
+	}
+
+	var withSecret bool
+	for _, detail := range s3bkt.Spec.ConnectionDetails {
+		withSecret = withSecret || detail.Target == s3v1alpha1.ConnectionDetailsTargetSecret
+	}
+	credentials := map[string]string{}
+	if withSecret {
+		var err error
+		if credentials, err = r.bucketCredentials(ctx, s3bkt); err != nil {
+			return nil, err
+		}
+	}
+
+	configMapData, secretData := renderConnectionDetails(s3bkt, facts, credentials)
+	if !withSecret {
+		return configMapData, r.deleteConnectionSecret(ctx, s3bkt)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(connectionSecretName, s3bkt.Name),
+			Namespace: s3bkt.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Data = map[string][]byte{}
+		for key, value := range secretData {
+			secret.Data[key] = []byte(value)
+		}
+		return controllerutil.SetControllerReference(s3bkt, secret, r.Scheme)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to write connection Secret: %w", err)
+	}
+	return configMapData, nil
+}
+
+// bucketCredentials returns the keys of the credentials Secret of the bucket, empty when there is none
+func (r *S3BucketReconciler) bucketCredentials(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (map[string]string, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf(credentialsSecretName, s3bkt.Name), Namespace: s3bkt.Namespace}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get credentials Secret: %w", err)
+	}
+	credentials := map[string]string{}
+	for key, value := range secret.Data {
+		credentials[key] = string(value)
+	}
+	return credentials, nil
+}
+
+// renderConnectionDetails renders spec.connectionDetails by target and sets the
+// ConnectionDetailsRendered condition
+func renderConnectionDetails(s3bkt *s3v1alpha1.S3Bucket, facts connectionFacts, credentials map[string]string) (map[string]string, map[string]string) {
+	configMapData, secretData := map[string]string{}, map[string]string{}
+	var failures []string
+	for _, detail := range s3bkt.Spec.ConnectionDetails {
+		target, data := facts, configMapData
+		// Credentials never end up in the ConfigMap
+		target.Credentials = nil
+		if detail.Target == s3v1alpha1.ConnectionDetailsTargetSecret {
+			target.Credentials, data = credentials, secretData
+		}
+
+		value, err := renderConnectionDetail(detail, target)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", detail.Key, err))
+			continue
+		}
+		data[detail.Key] = value
+	}
+
+	if len(failures) == 0 {
+		meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionConnectionDetailsRendered,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: s3bkt.Generation,
+			Reason:             "Rendered",
+			Message:            fmt.Sprintf("Rendered %d connection details", len(s3bkt.Spec.ConnectionDetails)),
+		})
+	} else {
+		meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionConnectionDetailsRendered,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: s3bkt.Generation,
+			Reason:             "TemplateError",
+			Message:            strings.Join(failures, "; "),
+		})
+	}
+	return configMapData, secretData
+}
+
+// renderConnectionDetail renders one connection details template; missing keys are errors
+func renderConnectionDetail(detail s3v1alpha1.ConnectionDetail, facts connectionFacts) (string, error) {
+	tmpl, err := template.New(detail.Key).Funcs(connectionTemplateFuncs).Option("missingkey=error").Parse(detail.Template)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, facts); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// deleteConnectionSecret deletes the connection Secret once no detail targets it
+func (r *S3BucketReconciler) deleteConnectionSecret(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf(connectionSecretName, s3bkt.Name), Namespace: s3bkt.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get connection Secret: %w", err)
+	}
+	if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete connection Secret: %w", err)
+	}
+	return nil
+}
+
+// renderedKeys returns the sorted keys of the rendered connection details, as recorded in the
+// connectionDetailsAnnotation
+func renderedKeys(details map[string]string) string {
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3Bucket connection details", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *S3BucketReconciler
+		s3bkt      *s3v1alpha1.S3Bucket
+	)
+
+	getConfigMap := func() *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}, cm)).To(Succeed())
+		return cm
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: "my-data-bucket"},
+		}
+		credentialsSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-s3-credentials", Namespace: "default"},
+			Data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("AKIAEXAMPLE"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt, credentialsSecret).Build()
+
+		sess, err := session.NewSession(&aws.Config{
+			Region:      aws.String("eu-west-1"),
+			Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, S3svc: s3.New(sess)}
+	})
+
+	It("should render ConfigMap keys over the bucket facts", func() {
+		s3bkt.Spec.ConnectionDetails = []s3v1alpha1.ConnectionDetail{
+			{Key: "S3_BUCKET", Template: "{{ .BucketName }}"},
+			{Key: "BUCKET_URL", Template: "s3://{{ .BucketName }}?region={{ .Region }}"},
+			{Key: "bucket.json", Template: `{"arn": {{ toJson .ARN }}, "endpoint": {{ toJson .Endpoint }}}`},
+			{Key: "application.properties", Template: "s3.bucket={{ .BucketName }}\ns3.url={{ .URL }}\n"},
+		}
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		cm := getConfigMap()
+		Expect(cm.Data).To(HaveKeyWithValue("S3_BUCKET", "my-data-bucket"))
+		Expect(cm.Data).To(HaveKeyWithValue("BUCKET_URL", "s3://my-data-bucket?region=eu-west-1"))
+		Expect(cm.Data).To(HaveKeyWithValue("bucket.json", `{"arn": "arn:aws:s3:::my-data-bucket", "endpoint": "https://s3.eu-west-1.amazonaws.com"}`))
+		Expect(cm.Data).To(HaveKeyWithValue("application.properties",
+			"s3.bucket=my-data-bucket\ns3.url=https://my-data-bucket.s3.eu-west-1.amazonaws.com\n"))
+		Expect(cm.Data).To(HaveKeyWithValue("BucketName", "my-data-bucket"))
+
+		condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should render credentials into the connection Secret only", func() {
+		s3bkt.Spec.ConnectionDetails = []s3v1alpha1.ConnectionDetail{
+			{
+				Key:      "S3_URL",
+				Template: "s3://{{ .Credentials.AWS_ACCESS_KEY_ID }}:{{ .Credentials.AWS_SECRET_ACCESS_KEY }}@{{ .BucketName }}",
+				Target:   s3v1alpha1.ConnectionDetailsTargetSecret,
+			},
+			{Key: "LEAKED_KEY", Template: "{{ .Credentials.AWS_SECRET_ACCESS_KEY }}"},
+		}
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-connection", Namespace: "default"}, secret)).To(Succeed())
+		Expect(secret.Data).To(Equal(map[string][]byte{"S3_URL": []byte("s3://AKIAEXAMPLE:secret@my-data-bucket")}))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(getConfigMap().Data).NotTo(HaveKey("LEAKED_KEY"))
+
+		condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("TemplateError"))
+		Expect(condition.Message).To(ContainSubstring("LEAKED_KEY"))
+	})
+
+	It("should report templates that do not parse", func() {
+		s3bkt.Spec.ConnectionDetails = []s3v1alpha1.ConnectionDetail{{Key: "BROKEN", Template: "{{ .BucketName "}}
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		Expect(getConfigMap().Data).NotTo(HaveKey("BROKEN"))
+		condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(HavePrefix("BROKEN: "))
+	})
+
+	It("should remove the keys of removed entries", func() {
+		s3bkt.Spec.ConnectionDetails = []s3v1alpha1.ConnectionDetail{
+			{Key: "S3_BUCKET", Template: "{{ .BucketName }}"},
+			{Key: "S3_REGION", Template: "{{ .Region }}", Target: s3v1alpha1.ConnectionDetailsTargetSecret},
+		}
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+		Expect(getConfigMap().Data).To(HaveKey("S3_BUCKET"))
+
+		s3bkt.Spec.ConnectionDetails = nil
+		Expect(reconciler.ensureBucketConfigMap(ctx, s3bkt, "")).To(Succeed())
+
+		cm := getConfigMap()
+		Expect(cm.Data).NotTo(HaveKey("S3_BUCKET"))
+		Expect(cm.Data).To(HaveKey("BucketName"))
+		Expect(cm.Annotations).NotTo(HaveKey(connectionDetailsAnnotation))
+		err := c.Get(ctx, client.ObjectKey{Name: "data-s3-connection", Namespace: "default"}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)).To(BeNil())
+	})
+})
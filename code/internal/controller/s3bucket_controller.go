@@ -18,20 +18,31 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/aws/aws-sdk-go/aws"               // AWS SDK for Go
-	"github.com/aws/aws-sdk-go/aws/awserr"        // For AWS error handling
-	"github.com/aws/aws-sdk-go/service/s3"        // S3 service client
-	corev1 "k8s.io/api/core/v1"                   // Core Kubernetes API types (like ConfigMap)
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1" // Meta types for Kubernetes resources (like ObjectMeta)
+	"github.com/aws/aws-sdk-go/aws"         // AWS SDK for Go
+	"github.com/aws/aws-sdk-go/aws/awserr"  // For AWS error handling
+	"github.com/aws/aws-sdk-go/service/iam" // IAM service client for scoped bucket credentials
+	"github.com/aws/aws-sdk-go/service/s3"  // S3 service client
+	corev1 "k8s.io/api/core/v1"             // Core Kubernetes API types (like ConfigMap)
+	"k8s.io/apimachinery/pkg/api/meta"      // For status condition helpers
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types" // For NamespacedName
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"time"
 
 	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/metrics"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"k8s.io/client-go/util/retry"                                                 // For retrying on conflict errors
 	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil" // For managing finalizers
 )
@@ -45,13 +56,37 @@ const (
 type S3BucketReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
-	S3svc  *s3.S3 // AWS S3 service client defined in main.go
+	S3svc  *s3.S3   // AWS S3 service client of the bucket being reconciled
+	IAMsvc *iam.IAM // AWS IAM service client of the bucket being reconciled
+	// Clients hands out the clients of each S3ProviderConfig, defined in main.go
+	Clients *s3client.Cache
+	// DriftInterval is how often created buckets are checked for unmanaged configurations; zero
+	// only checks them when the spec changes
+	DriftInterval time.Duration
+	// UsageInterval is how often the usage of each bucket is collected; zero disables collection
+	UsageInterval time.Duration
+	// UsageMaxObjects bounds the objects counted when a bucket is listed to measure its usage
+	UsageMaxObjects int64
+	// Recorder emits the lifecycle events of the buckets
+	Recorder *recorder.Recorder
+
+	// clients are the clients resolved for the bucket being reconciled
+	clients *s3client.Clients
+	// manager manages the bucket being reconciled when its provider is not AWS
+	manager provider.BucketManager
+	// states are the last states reported by the bucket managers, shared by the copies of the
+	// reconciler made for each bucket; set up by SetupWithManager
+	states *bucketStates
 }
 
 // +kubebuilder:rbac:groups=s3.acme.io,resources=s3buckets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=s3.acme.io,resources=s3buckets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=s3.acme.io,resources=s3buckets/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -63,17 +98,46 @@ type S3BucketReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.21.0/pkg/reconcile
 
-func (r *S3BucketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *S3BucketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
+	// Trace the reconcile; S3 calls and Kubernetes writes become child spans
+	ctx, span := tracing.StartReconcile(ctx, "S3Bucket.Reconcile",
+		semconv.K8SNamespaceName(req.Namespace), attribute.String("s3bucket.name", req.Name))
+	defer func() { tracing.End(span, err) }()
+
 	log := logf.FromContext(ctx)
 	log.Info("Reconciling S3Bucket", "NamespacedName", req.NamespacedName)
 
 	// Fetch the S3Bucket resource
 	s3bkt := &s3v1alpha1.S3Bucket{}
-	err := r.Get(ctx, req.NamespacedName, s3bkt)
+	err = r.Get(ctx, req.NamespacedName, s3bkt)
 	if err != nil {
 		log.Info("S3Bucket resource not found, ignoring since object must be deleted")
 		return ctrl.Result{}, nil
 	}
+	span.SetAttributes(
+		semconv.AWSS3Bucket(s3bkt.Spec.Name),
+		attribute.String("s3bucket.state", s3bkt.Status.State),
+		attribute.String("s3bucket.provider_config", s3bkt.Spec.ProviderConfigRef),
+		attribute.Int64("s3bucket.generation", s3bkt.Generation),
+	)
+
+	// Skip every remote change, deletion included, while the bucket is paused
+	paused := isPaused(s3bkt)
+	if err := r.setPausedCondition(ctx, s3bkt, paused); err != nil {
+		log.Error(err, "Failed to update Paused condition", "BucketName", s3bkt.Spec.Name)
+		return ctrl.Result{}, err
+	}
+	if paused {
+		log.Info("Reconciliation is paused", "BucketName", s3bkt.Spec.Name, "Annotation", s3v1alpha1.PausedAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	// Use the clients of the provider config the bucket references
+	r, err = r.withProviderConfig(ctx, s3bkt)
+	if err != nil {
+		log.Error(err, "Failed to resolve provider clients for bucket", "BucketName", s3bkt.Spec.Name)
+		return ctrl.Result{}, err
+	}
 
 	// Check if the resource is being deleted
 	if !s3bkt.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -81,10 +145,17 @@ func (r *S3BucketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Info("S3Bucket is being deleted", "BucketName", s3bkt.Spec.Name)
 
 		if controllerutil.ContainsFinalizer(s3bkt, s3BucketFinalizer) {
+			// Wait for the backoff of the last failed deletion
+			if wait, pending := pendingRetry(s3bkt, true, r.credentialsVersion()); pending {
+				return ctrl.Result{RequeueAfter: wait}, nil
+			}
 			// Our finalizer is present, so handle deletion
-			if err := r.DeleteResource(ctx, s3bkt); err != nil {
+			err := r.DeleteResource(ctx, s3bkt)
+			metrics.ObserveBucketReconcile(metrics.PhaseDelete, err)
+			if err != nil {
+				r.Recorder.Warning(s3bkt, recorder.ReasonDeletionBlocked, "Failed to delete bucket "+s3bkt.Spec.Name, err)
 				log.Error(err, "Failed to delete S3 bucket resources")
-				return ctrl.Result{}, err
+				return r.retryAfterFailure(ctx, s3bkt, metrics.PhaseDelete, err)
 			}
 			// Remove finalizer after successful deletion
 			controllerutil.RemoveFinalizer(s3bkt, s3BucketFinalizer)
@@ -106,27 +177,76 @@ func (r *S3BucketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Wait for the backoff of the last failure unless the spec or the credentials changed since
+	if wait, pending := pendingRetry(s3bkt, false, r.credentialsVersion()); pending {
+		log.Info("Waiting for the backoff of the last failure", "BucketName", s3bkt.Spec.Name, "RetryAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
 	// Handle creation or update logic based on current state
 	switch s3bkt.Status.State {
-	case "":
-		// New resource - create it
-		log.Info("Creating new S3 bucket", "BucketName", s3bkt.Spec.Name)
-		if err := r.CreateResource(ctx, s3bkt); err != nil {
+	case "", s3v1alpha1.ERROR_STATE:
+		// New resource, or a failed creation whose backoff elapsed - create it
+		log.Info("Creating new S3 bucket", "BucketName", s3bkt.Spec.Name, "State", s3bkt.Status.State)
+		r.Recorder.Normal(s3bkt, recorder.ReasonCreating, "Creating bucket %s", s3bkt.Spec.Name)
+		err := r.CreateResource(ctx, s3bkt)
+		metrics.ObserveBucketReconcile(metrics.PhaseCreate, err)
+		if err != nil {
 			log.Error(err, "Failed to create S3 bucket")
-			return ctrl.Result{}, err
+			r.Recorder.Warning(s3bkt, recorder.ReasonCreateFailed, "Failed to create bucket "+s3bkt.Spec.Name, err)
+			return r.retryAfterFailure(ctx, s3bkt, metrics.PhaseCreate, err)
 		}
-		return ctrl.Result{}, nil
+		r.Recorder.Normal(s3bkt, recorder.ReasonCreated, "Bucket %s is ready", s3bkt.Spec.Name)
+		return ctrl.Result{}, r.resetRetry(ctx, s3bkt)
 
 	case s3v1alpha1.CREATED_STATE:
 		// Resource exists and is healthy
 		log.Info("S3 bucket is in CREATED state", "BucketName", s3bkt.Spec.Name)
-		// Add any update/sync logic here if needed
-		return ctrl.Result{}, nil
-
-	case s3v1alpha1.ERROR_STATE:
-		// Resource is in error state - might want to retry or alert
-		log.Info("S3 bucket is in ERROR state", "BucketName", s3bkt.Spec.Name)
-		return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
+		// Apply spec changes made after the bucket was created
+		if s3bkt.Status.ObservedGeneration != s3bkt.Generation {
+			err := r.SyncResource(ctx, s3bkt)
+			metrics.ObserveBucketReconcile(metrics.PhaseSync, err)
+			if err != nil {
+				log.Error(err, "Failed to sync S3 bucket configuration")
+				r.Recorder.Warning(s3bkt, recorder.ReasonConfigFailed,
+					fmt.Sprintf("Failed to apply generation %d to bucket %s", s3bkt.Generation, s3bkt.Spec.Name), err)
+				return r.retryAfterFailure(ctx, s3bkt, metrics.PhaseSync, err)
+			}
+			r.Recorder.Normal(s3bkt, recorder.ReasonConfigApplied, "Applied generation %d to bucket %s", s3bkt.Generation, s3bkt.Spec.Name)
+			return ctrl.Result{}, r.resetRetry(ctx, s3bkt)
+		}
+		// Restore the ConfigMap if it was edited or deleted
+		err := r.repairBucketConfigMap(ctx, s3bkt)
+		metrics.ObserveBucketReconcile(metrics.PhaseRepair, err)
+		if err != nil {
+			log.Error(err, "Failed to repair bucket ConfigMap")
+			return r.retryAfterFailure(ctx, s3bkt, metrics.PhaseRepair, err)
+		}
+		if err := r.resetRetry(ctx, s3bkt); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Refresh the storage usage of the bucket once per usage interval
+		requeueAfter, err := r.refreshBucketUsage(ctx, s3bkt)
+		if err != nil {
+			log.Error(err, "Failed to collect bucket usage", "BucketName", s3bkt.Spec.Name)
+		}
+		// Look for configurations added outside the operator once per drift interval
+		driftAfter, err := r.refreshDrift(ctx, s3bkt)
+		if err != nil {
+			log.Error(err, "Failed to check bucket drift", "BucketName", s3bkt.Spec.Name)
+		}
+		if driftAfter > 0 && (requeueAfter == 0 || driftAfter < requeueAfter) {
+			requeueAfter = driftAfter
+		}
+		// Renew the credentials issued for the bucket before they expire
+		renewAfter, err := r.credentialsRenewal(ctx, s3bkt)
+		if err != nil {
+			log.Error(err, "Failed to check bucket credentials", "BucketName", s3bkt.Spec.Name)
+		}
+		if renewAfter > 0 && (requeueAfter == 0 || renewAfter < requeueAfter) {
+			requeueAfter = renewAfter
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 
 	case s3v1alpha1.CREATING_STATE, s3v1alpha1.DELETING_STATE:
 		// Transitional state - requeue to check later
@@ -141,8 +261,12 @@ func (r *S3BucketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *S3BucketReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.states = newBucketStates()
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&s3v1alpha1.S3Bucket{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Watches(&s3v1alpha1.S3ProviderConfig{}, handler.EnqueueRequestsFromMapFunc(r.bucketsForProviderConfig)).
 		Named("s3bucket").
 		Complete(r)
 }
@@ -157,6 +281,15 @@ func (r *S3BucketReconciler) CreateResource(ctx context.Context, s3bkt *s3v1alph
 		return fmt.Errorf("failed to update status to CREATING: %w", err)
 	}
 
+	// Buckets of other providers are created through their bucket manager
+	if r.manager != nil {
+		if err := r.ensureManagedBucket(ctx, s3bkt); err != nil {
+			r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.ERROR_STATE)
+			return err
+		}
+		return r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.CREATED_STATE, recordObservedConfiguration(s3bkt))
+	}
+
 	// Create the S3 bucket
 	bucketOutput, err := r.createS3Bucket(ctx, s3bkt)
 	if err != nil {
@@ -170,14 +303,26 @@ func (r *S3BucketReconciler) CreateResource(ctx context.Context, s3bkt *s3v1alph
 		return fmt.Errorf("bucket creation timeout: %w", err)
 	}
 
+	// Apply the optional bucket configuration (website, acceleration, ...)
+	if err := r.applyBucketConfiguration(ctx, s3bkt); err != nil {
+		r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.ERROR_STATE)
+		return err
+	}
+
 	// Create ConfigMap with bucket details
-	if err := r.createBucketConfigMap(ctx, s3bkt, bucketOutput); err != nil {
+	if err := r.ensureBucketConfigMap(ctx, s3bkt, aws.StringValue(bucketOutput.Location)); err != nil {
 		r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.ERROR_STATE)
 		return fmt.Errorf("failed to create ConfigMap: %w", err)
 	}
 
+	// Expose the website endpoint as an ExternalName Service if requested
+	if err := r.ensureWebsiteService(ctx, s3bkt, s3bkt.Status.WebsiteEndpoint); err != nil {
+		r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.ERROR_STATE)
+		return err
+	}
+
 	// Update status to CREATED
-	if err := r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.CREATED_STATE); err != nil {
+	if err := r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.CREATED_STATE, recordObservedConfiguration(s3bkt)); err != nil {
 		return fmt.Errorf("failed to update status to CREATED: %w", err)
 	}
 
@@ -185,8 +330,146 @@ func (r *S3BucketReconciler) CreateResource(ctx context.Context, s3bkt *s3v1alph
 	return nil
 }
 
-// updateBucketStatus updates the bucket status with retry logic to handle conflicts
-func (r *S3BucketReconciler) updateBucketStatus(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, state string) error {
+// SyncResource applies spec changes to an existing S3 bucket and its ConfigMap
+func (r *S3BucketReconciler) SyncResource(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+	log.Info("Syncing S3 Bucket configuration", "BucketName", s3bkt.Spec.Name, "Generation", s3bkt.Generation)
+
+	if r.manager != nil {
+		if err := r.ensureManagedBucket(ctx, s3bkt); err != nil {
+			return err
+		}
+		return r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.CREATED_STATE, recordObservedConfiguration(s3bkt))
+	}
+
+	if err := r.applyBucketConfiguration(ctx, s3bkt); err != nil {
+		return err
+	}
+
+	if err := r.ensureBucketConfigMap(ctx, s3bkt, ""); err != nil {
+		return err
+	}
+
+	if err := r.ensureWebsiteService(ctx, s3bkt, s3bkt.Status.WebsiteEndpoint); err != nil {
+		return err
+	}
+
+	return r.updateBucketStatus(ctx, s3bkt, s3v1alpha1.CREATED_STATE, recordObservedConfiguration(s3bkt))
+}
+
+// applyBucketConfiguration applies the optional bucket configuration from the spec
+// and records the observed results in the in-memory status
+func (r *S3BucketReconciler) applyBucketConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	accountID, err := r.ownerAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to look up the owning account: %w", err)
+	}
+	s3bkt.Status.AccountID = accountID
+
+	// S3-compatible backends may lack some APIs; requested ones are reported instead of failing
+	var unsupported unsupportedFeatures
+	unmanaged := map[string][]string{}
+	unmanaged[retentionKind], err = r.applyObjectLockConfiguration(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureObjectLock, s3bkt.Spec.Locked); err != nil {
+		return fmt.Errorf("failed to configure object lock: %w", err)
+	}
+	if s3bkt.Spec.RetentionDays > 0 && !s3bkt.Spec.Locked {
+		// S3 only retains objects through object lock
+		unsupported = append(unsupported, featureRetentionWithoutLock)
+	}
+	unmanaged[versioningKind], err = r.applyVersioningConfiguration(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureVersioning, s3bkt.Spec.Versioning); err != nil {
+		return fmt.Errorf("failed to configure versioning: %w", err)
+	}
+	if err := unsupported.tolerate(r.applyTagging(ctx, s3bkt), featureTags, len(s3bkt.Spec.Tags) > 0); err != nil {
+		return fmt.Errorf("failed to configure tags: %w", err)
+	}
+	if err := unsupported.tolerate(r.applyLifecycleConfiguration(ctx, s3bkt), featureLifecycle, len(s3bkt.Spec.Lifecycle) > 0); err != nil {
+		return fmt.Errorf("failed to configure lifecycle: %w", err)
+	}
+
+	endpoint, err := r.applyWebsiteConfiguration(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureWebsite, s3bkt.Spec.Website != nil); err != nil {
+		return fmt.Errorf("failed to configure website hosting: %w", err)
+	}
+	s3bkt.Status.WebsiteEndpoint = endpoint
+
+	accelerationStatus, err := r.applyAccelerateConfiguration(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureAcceleration, s3bkt.Spec.Acceleration); err != nil {
+		return fmt.Errorf("failed to configure transfer acceleration: %w", err)
+	}
+	s3bkt.Status.AccelerationStatus = accelerationStatus
+
+	requestPayer, err := r.applyRequestPaymentConfiguration(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureRequesterPays, s3bkt.Spec.RequesterPays); err != nil {
+		return fmt.Errorf("failed to configure request payment: %w", err)
+	}
+	s3bkt.Status.RequestPayer = requestPayer
+
+	unmanaged[inventoryKind], err = r.applyInventoryConfigurations(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureInventory, len(s3bkt.Spec.Inventory) > 0); err != nil {
+		return fmt.Errorf("failed to configure inventory: %w", err)
+	}
+	unmanaged[analyticsKind], err = r.applyAnalyticsConfigurations(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureAnalytics, len(s3bkt.Spec.Analytics) > 0); err != nil {
+		return fmt.Errorf("failed to configure analytics: %w", err)
+	}
+	unmanaged[intelligentTieringKind], err = r.applyIntelligentTieringConfigurations(ctx, s3bkt)
+	if err = unsupported.tolerate(err, featureIntelligentTiering, len(s3bkt.Spec.IntelligentTiering) > 0); err != nil {
+		return fmt.Errorf("failed to configure intelligent-tiering: %w", err)
+	}
+	r.recordDrift(s3bkt, unmanaged)
+	now := metav1.Now()
+	s3bkt.Status.LastDriftCheckTime = &now
+	setFeaturesCondition(s3bkt, unsupported)
+
+	if err := r.reconcileAccess(ctx, s3bkt); err != nil {
+		return fmt.Errorf("failed to provision scoped access: %w", err)
+	}
+
+	return nil
+}
+
+// recordObservedConfiguration returns a status mutator that copies the configuration
+// observed by applyBucketConfiguration onto the latest status
+func recordObservedConfiguration(s3bkt *s3v1alpha1.S3Bucket) func(*s3v1alpha1.S3BucketStatus) {
+	return func(status *s3v1alpha1.S3BucketStatus) {
+		status.ObservedGeneration = s3bkt.Generation
+		status.AccountID = s3bkt.Status.AccountID
+		status.LastDriftCheckTime = s3bkt.Status.LastDriftCheckTime
+		status.WebsiteEndpoint = s3bkt.Status.WebsiteEndpoint
+		status.AccelerationStatus = s3bkt.Status.AccelerationStatus
+		status.RequestPayer = s3bkt.Status.RequestPayer
+		status.Access = s3bkt.Status.Access
+		status.Binding = s3bkt.Status.Binding
+		for _, condition := range s3bkt.Status.Conditions {
+			meta.SetStatusCondition(&status.Conditions, condition)
+		}
+		if meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered) == nil {
+			meta.RemoveStatusCondition(&status.Conditions, s3v1alpha1.ConditionConnectionDetailsRendered)
+		}
+	}
+}
+
+// configurationData returns the ConfigMap keys derived from the bucket configuration.
+// Keys of features that are not configured have empty values.
+func configurationData(s3bkt *s3v1alpha1.S3Bucket) map[string]string {
+	data := map[string]string{
+		"WebsiteEndpoint":     s3bkt.Status.WebsiteEndpoint,
+		"AccelerateEndpoint":  "",
+		"RequesterPays":       fmt.Sprintf("%t", s3bkt.Status.RequestPayer == s3.PayerRequester),
+		"DefaultStorageClass": s3bkt.Spec.DefaultStorageClass,
+	}
+	if s3bkt.Status.AccelerationStatus == s3.BucketAccelerateStatusEnabled {
+		data["AccelerateEndpoint"] = accelerateEndpoint(s3bkt.Spec.Name)
+	}
+	return data
+}
+
+// updateBucketStatus updates the bucket status with retry logic to handle conflicts.
+// Optional mutators can set additional status fields alongside the state; an empty state
+// keeps the current one.
+func (r *S3BucketReconciler) updateBucketStatus(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, state string, mutators ...func(*s3v1alpha1.S3BucketStatus)) error {
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// Always fetch the latest version to avoid conflicts
 		latest := &s3v1alpha1.S3Bucket{}
@@ -198,7 +481,12 @@ func (r *S3BucketReconciler) updateBucketStatus(ctx context.Context, s3bkt *s3v1
 		}
 
 		// Update the status field
-		latest.Status.State = state
+		if state != "" {
+			latest.Status.State = state
+		}
+		for _, mutate := range mutators {
+			mutate(&latest.Status)
+		}
 		return r.Status().Update(ctx, latest)
 	})
 }
@@ -208,10 +496,27 @@ func (r *S3BucketReconciler) createS3Bucket(ctx context.Context, s3bkt *s3v1alph
 	log := logf.FromContext(ctx)
 	log.Info("Creating S3 bucket", "BucketName", s3bkt.Spec.Name)
 
-	output, err := r.S3svc.CreateBucket(&s3.CreateBucketInput{
+	input := &s3.CreateBucketInput{
 		Bucket:                     aws.String(s3bkt.Spec.Name),
 		ObjectLockEnabledForBucket: aws.Bool(s3bkt.Spec.Locked),
-	})
+	}
+	// The client is in the region of the bucket; us-east-1 takes no location constraint
+	if region := aws.StringValue(r.S3svc.Config.Region); region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{LocationConstraint: aws.String(region)}
+	}
+	output, err := r.S3svc.CreateBucketWithContext(ctx, input)
+	if err != nil && s3bkt.Spec.Locked && isUnsupported(err) {
+		// Some S3-compatible backends lack object lock; the FeaturesSupported condition reports it
+		log.Info("Object lock not supported by the storage backend, creating the bucket without it", "BucketName", s3bkt.Spec.Name)
+		input.ObjectLockEnabledForBucket = nil
+		output, err = r.S3svc.CreateBucketWithContext(ctx, input)
+	}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeBucketAlreadyOwnedByYou {
+		// A retried creation finds the bucket created by the failed attempt
+		log.Info("S3 bucket already owned by the operator account", "BucketName", s3bkt.Spec.Name)
+		return &s3.CreateBucketOutput{Location: aws.String("/" + s3bkt.Spec.Name)}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("S3 CreateBucket API call failed: %w", err)
 	}
@@ -220,11 +525,14 @@ func (r *S3BucketReconciler) createS3Bucket(ctx context.Context, s3bkt *s3v1alph
 }
 
 // waitForBucketReady waits until the bucket exists and is ready
-func (r *S3BucketReconciler) waitForBucketReady(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+func (r *S3BucketReconciler) waitForBucketReady(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "waitForBucketReady")
+	defer func() { tracing.End(span, err) }()
+
 	log := logf.FromContext(ctx)
 	log.Info("Waiting for bucket to be ready", "BucketName", s3bkt.Spec.Name)
 
-	err := r.S3svc.WaitUntilBucketExists(&s3.HeadBucketInput{
+	err = r.S3svc.WaitUntilBucketExistsWithContext(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(s3bkt.Spec.Name),
 	})
 	if err != nil {
@@ -234,34 +542,38 @@ func (r *S3BucketReconciler) waitForBucketReady(ctx context.Context, s3bkt *s3v1
 	return nil
 }
 
-// createBucketConfigMap creates a ConfigMap containing bucket metadata
-func (r *S3BucketReconciler) createBucketConfigMap(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, bucketOutput *s3.CreateBucketOutput) error {
-	log := logf.FromContext(ctx)
-	log.Info("Creating ConfigMap for bucket", "BucketName", s3bkt.Spec.Name)
-
-	data := map[string]string{
-		"BucketName": s3bkt.Spec.Name,
-		"Region":     s3bkt.Spec.Region,
-		"Locked":     fmt.Sprintf("%t", s3bkt.Spec.Locked),
-		"location":   aws.StringValue(bucketOutput.Location),
+// withProviderConfig returns a copy of the reconciler using the AWS clients of the provider
+// config and namespace of the bucket, so concurrent reconciles of buckets in other accounts are unaffected
+func (r *S3BucketReconciler) withProviderConfig(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*S3BucketReconciler, error) {
+	if r.Clients == nil {
+		return r, nil
 	}
-
-	cm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf(configMapName, s3bkt.Name),
-			Namespace: s3bkt.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(s3bkt, s3v1alpha1.GroupVersion.WithKind("S3Bucket")),
-			},
-		},
-		Data: data,
+	clients, err := r.Clients.Get(ctx, s3bkt.Spec.ProviderConfigRef, s3bkt.Namespace)
+	if err != nil {
+		return nil, err
 	}
+	scoped := *r
+	scoped.S3svc = clients.S3In(s3bkt.Spec.Region)
+	scoped.IAMsvc = clients.IAM
+	scoped.clients = clients
+	scoped.manager = clients.Manager
+	return &scoped, nil
+}
 
-	if err := r.Create(ctx, cm); err != nil {
-		return fmt.Errorf("failed to create ConfigMap: %w", err)
+// ownerAccount returns the AWS account the bucket is managed in, or "" when it is unknown
+func (r *S3BucketReconciler) ownerAccount(ctx context.Context) (string, error) {
+	if r.clients == nil {
+		return "", nil
 	}
+	return r.clients.AccountID(ctx)
+}
 
-	return nil
+// bucketRegion returns the region the bucket lives in, falling back to the region of the S3 client
+func (r *S3BucketReconciler) bucketRegion(s3bkt *s3v1alpha1.S3Bucket) string {
+	if s3bkt.Spec.Region != "" {
+		return s3bkt.Spec.Region
+	}
+	return aws.StringValue(r.S3svc.Config.Region)
 }
 
 // DeleteResource handles the complete deletion flow including finalizer management
@@ -291,6 +603,7 @@ func (r *S3BucketReconciler) DeleteResource(ctx context.Context, s3bkt *s3v1alph
 	if err := r.removeFinalizer(ctx, s3bkt); err != nil {
 		return fmt.Errorf("failed to remove finalizer: %w", err)
 	}
+	r.Recorder.Normal(s3bkt, recorder.ReasonFinalizerRemoved, "Deleted bucket %s and removed the finalizer", s3bkt.Spec.Name)
 
 	log.Info("S3 Bucket deleted successfully and finalizer removed", "BucketName", s3bkt.Spec.Name)
 	return nil
@@ -300,6 +613,11 @@ func (r *S3BucketReconciler) DeleteResource(ctx context.Context, s3bkt *s3v1alph
 func (r *S3BucketReconciler) performCleanup(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
 	log := logf.FromContext(ctx)
 
+	// Buckets of other providers are deleted through their bucket manager
+	if r.manager != nil {
+		return r.deleteManagedBucket(ctx, s3bkt)
+	}
+
 	// Delete the S3 bucket
 	if err := r.deleteS3Bucket(ctx, s3bkt); err != nil {
 		return fmt.Errorf("failed to delete S3 bucket: %w", err)
@@ -310,6 +628,13 @@ func (r *S3BucketReconciler) performCleanup(ctx context.Context, s3bkt *s3v1alph
 		return fmt.Errorf("bucket deletion timeout: %w", err)
 	}
 
+	// Delete the scoped IAM identity and its credentials Secret
+	if s3bkt.Spec.Access != nil || s3bkt.Status.Access != nil {
+		if err := r.deleteAccess(ctx, s3bkt); err != nil {
+			return fmt.Errorf("failed to delete scoped access: %w", err)
+		}
+	}
+
 	// Delete the ConfigMap (best effort - don't fail if it doesn't exist)
 	if err := r.deleteBucketConfigMap(ctx, s3bkt); err != nil {
 		log.Error(err, "Failed to delete ConfigMap, but bucket is deleted", "BucketName", s3bkt.Spec.Name)
@@ -366,7 +691,7 @@ func (r *S3BucketReconciler) deleteS3Bucket(ctx context.Context, s3bkt *s3v1alph
 	log := logf.FromContext(ctx)
 	log.Info("Deleting S3 bucket", "BucketName", s3bkt.Spec.Name)
 
-	_, err := r.S3svc.DeleteBucket(&s3.DeleteBucketInput{
+	_, err := r.S3svc.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{
 		Bucket: aws.String(s3bkt.Spec.Name),
 	})
 	if err != nil {
@@ -387,11 +712,14 @@ func (r *S3BucketReconciler) deleteS3Bucket(ctx context.Context, s3bkt *s3v1alph
 }
 
 // waitForBucketDeleted waits until the bucket is fully deleted
-func (r *S3BucketReconciler) waitForBucketDeleted(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+func (r *S3BucketReconciler) waitForBucketDeleted(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "waitForBucketDeleted")
+	defer func() { tracing.End(span, err) }()
+
 	log := logf.FromContext(ctx)
 	log.Info("Waiting for bucket to be deleted", "BucketName", s3bkt.Spec.Name)
 
-	err := r.S3svc.WaitUntilBucketNotExists(&s3.HeadBucketInput{
+	err = r.S3svc.WaitUntilBucketNotExistsWithContext(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(s3bkt.Spec.Name),
 	})
 	if err != nil {
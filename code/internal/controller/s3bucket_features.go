@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// Features reported in the FeaturesSupported condition
+const (
+	featureObjectLock           = "object lock"
+	featureRetentionWithoutLock = "retention without object lock"
+	featureVersioning           = "versioning"
+	featureTags                 = "tags"
+	featureLifecycle            = "lifecycle"
+	featureWebsite              = "website"
+	featureAcceleration         = "transfer acceleration"
+	featureRequesterPays        = "requester pays"
+	featureInventory            = "inventory"
+	featureAnalytics            = "analytics"
+	featureIntelligentTiering   = "intelligent-tiering"
+)
+
+// unsupportedErrorCodes are returned by S3-compatible backends such as MinIO and Ceph
+// for APIs they do not implement
+var unsupportedErrorCodes = map[string]bool{
+	"NotImplemented":  true,
+	"NotSupported":    true,
+	"XNotImplemented": true,
+}
+
+// isUnsupported reports whether err means the storage backend does not implement the API called
+func isUnsupported(err error) bool {
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) && requestErr.StatusCode() == http.StatusNotImplemented {
+		return true
+	}
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && unsupportedErrorCodes[aerr.Code()]
+}
+
+// unsupportedFeatures collects the requested features the storage backend does not implement
+type unsupportedFeatures []string
+
+// tolerate swallows err when it only means the backend lacks feature, recording the
+// feature if the spec requests it. Any other error is returned unchanged.
+func (u *unsupportedFeatures) tolerate(err error, feature string, requested bool) error {
+	if err == nil || !isUnsupported(err) {
+		return err
+	}
+	if requested {
+		*u = append(*u, feature)
+	}
+	return nil
+}
+
+// setFeaturesCondition reports the requested features the storage backend does not implement
+func setFeaturesCondition(s3bkt *s3v1alpha1.S3Bucket, unsupported unsupportedFeatures) {
+	if len(unsupported) == 0 {
+		meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionFeaturesSupported,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: s3bkt.Generation,
+			Reason:             "AllSupported",
+			Message:            "The storage backend implements every requested feature",
+		})
+		return
+	}
+
+	meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+		Type:               s3v1alpha1.ConditionFeaturesSupported,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: s3bkt.Generation,
+		Reason:             "NotImplemented",
+		Message:            fmt.Sprintf("The storage backend does not implement: %s", strings.Join(unsupported, ", ")),
+	})
+}
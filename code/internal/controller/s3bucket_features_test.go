@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3Bucket feature detection", func() {
+	notImplemented := fmt.Errorf("S3 GetBucketAccelerateConfiguration API call failed: %w",
+		awserr.NewRequestFailure(awserr.New("NotImplemented", "A header you provided implies functionality that is not implemented", nil),
+			http.StatusNotImplemented, "request-id"))
+
+	It("should recognize errors of unimplemented APIs", func() {
+		Expect(isUnsupported(notImplemented)).To(BeTrue())
+		Expect(isUnsupported(awserr.New("XNotImplemented", "not implemented", nil))).To(BeTrue())
+		Expect(isUnsupported(awserr.NewRequestFailure(awserr.New("AccessDenied", "denied", nil), http.StatusForbidden, "id"))).To(BeFalse())
+		Expect(isUnsupported(fmt.Errorf("connection refused"))).To(BeFalse())
+	})
+
+	It("should only report unsupported features the spec requests", func() {
+		var unsupported unsupportedFeatures
+		Expect(unsupported.tolerate(notImplemented, featureAcceleration, true)).To(Succeed())
+		Expect(unsupported.tolerate(notImplemented, featureInventory, false)).To(Succeed())
+		Expect(unsupported.tolerate(nil, featureWebsite, true)).To(Succeed())
+		Expect(unsupported).To(Equal(unsupportedFeatures{featureAcceleration}))
+
+		denied := awserr.New("AccessDenied", "denied", nil)
+		Expect(unsupported.tolerate(denied, featureWebsite, true)).To(MatchError(denied))
+	})
+
+	It("should report unsupported features in the FeaturesSupported condition", func() {
+		s3bkt := &s3v1alpha1.S3Bucket{}
+		setFeaturesCondition(s3bkt, unsupportedFeatures{featureObjectLock, featureAcceleration})
+		condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionFeaturesSupported)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring("object lock, transfer acceleration"))
+
+		setFeaturesCondition(s3bkt, nil)
+		Expect(meta.IsStatusConditionTrue(s3bkt.Status.Conditions, s3v1alpha1.ConditionFeaturesSupported)).To(BeTrue())
+	})
+})
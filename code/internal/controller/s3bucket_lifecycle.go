@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+const (
+	// errCodeNoSuchTagSet is returned by GetBucketTagging when the bucket has no tags
+	errCodeNoSuchTagSet = "NoSuchTagSet"
+	// errCodeObjectLockNotFound is returned by GetObjectLockConfiguration when object lock is not enabled
+	errCodeObjectLockNotFound = "ObjectLockConfigurationNotFoundError"
+)
+
+// applyVersioningConfiguration enables versioning when it is requested. Versioning enabled outside
+// the operator is only suspended under the Enforce drift policy, otherwise it is returned as drift
+func (r *S3BucketReconciler) applyVersioningConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	log := logf.FromContext(ctx)
+
+	current, err := r.S3svc.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("S3 GetBucketVersioning API call failed: %w", err)
+	}
+
+	observed := aws.StringValue(current.Status)
+	desired := s3.BucketVersioningStatusEnabled
+	if !s3bkt.Spec.Versioning {
+		// Versioning can only be suspended once enabled, and object lock keeps it enabled
+		if observed != s3.BucketVersioningStatusEnabled || s3bkt.Spec.Locked {
+			return nil, nil
+		}
+		if s3bkt.Spec.DriftPolicy != s3v1alpha1.DriftPolicyEnforce {
+			return []string{observed}, nil
+		}
+		desired = s3.BucketVersioningStatusSuspended
+	}
+	if observed == desired {
+		return nil, nil
+	}
+
+	log.Info("Updating versioning", "BucketName", s3bkt.Spec.Name, "Status", desired)
+	_, err = r.S3svc.PutBucketVersioningWithContext(ctx, &s3.PutBucketVersioningInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(desired),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("S3 PutBucketVersioning API call failed: %w", err)
+	}
+	return nil, nil
+}
+
+// applyTagging replaces the bucket tags with the spec. Without declared tags, the tags of the
+// bucket are only removed under the Enforce drift policy
+func (r *S3BucketReconciler) applyTagging(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+
+	if len(s3bkt.Spec.Tags) == 0 && s3bkt.Spec.DriftPolicy != s3v1alpha1.DriftPolicyEnforce {
+		return nil
+	}
+
+	current, err := r.S3svc.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+	})
+	observed := map[string]string{}
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != errCodeNoSuchTagSet {
+			return fmt.Errorf("S3 GetBucketTagging API call failed: %w", err)
+		}
+	} else {
+		for _, tag := range current.TagSet {
+			observed[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+
+	if equalStringMaps(observed, s3bkt.Spec.Tags) {
+		return nil
+	}
+
+	if len(s3bkt.Spec.Tags) == 0 {
+		log.Info("Removing bucket tags", "BucketName", s3bkt.Spec.Name)
+		if _, err := r.S3svc.DeleteBucketTaggingWithContext(ctx, &s3.DeleteBucketTaggingInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+		}); err != nil {
+			return fmt.Errorf("S3 DeleteBucketTagging API call failed: %w", err)
+		}
+		return nil
+	}
+
+	keys := make([]string, 0, len(s3bkt.Spec.Tags))
+	for key := range s3bkt.Spec.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tagSet := make([]*s3.Tag, 0, len(keys))
+	for _, key := range keys {
+		tagSet = append(tagSet, &s3.Tag{Key: aws.String(key), Value: aws.String(s3bkt.Spec.Tags[key])})
+	}
+
+	log.Info("Updating bucket tags", "BucketName", s3bkt.Spec.Name)
+	if _, err := r.S3svc.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(s3bkt.Spec.Name),
+		Tagging: &s3.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return fmt.Errorf("S3 PutBucketTagging API call failed: %w", err)
+	}
+	return nil
+}
+
+// buildLifecycleConfiguration converts the lifecycle rules into the S3 API representation
+func buildLifecycleConfiguration(rules []s3v1alpha1.LifecycleRule) *s3.BucketLifecycleConfiguration {
+	config := &s3.BucketLifecycleConfiguration{}
+	for _, rule := range rules {
+		lifecycleRule := &s3.LifecycleRule{
+			ID:     aws.String(rule.ID),
+			Status: aws.String(s3.ExpirationStatusEnabled),
+			Filter: &s3.LifecycleRuleFilter{Prefix: aws.String(rule.Prefix)},
+		}
+		if rule.ExpirationDays > 0 {
+			lifecycleRule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(int64(rule.ExpirationDays))}
+		}
+		if rule.NoncurrentVersionExpirationDays > 0 {
+			lifecycleRule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+				NoncurrentDays: aws.Int64(int64(rule.NoncurrentVersionExpirationDays)),
+			}
+		}
+		for _, transition := range rule.Transitions {
+			lifecycleRule.Transitions = append(lifecycleRule.Transitions, &s3.Transition{
+				Days:         aws.Int64(int64(transition.Days)),
+				StorageClass: aws.String(transition.StorageClass),
+			})
+		}
+		config.Rules = append(config.Rules, lifecycleRule)
+	}
+	return config
+}
+
+// applyLifecycleConfiguration replaces the lifecycle rules of the bucket with the spec. Without
+// declared rules, the rules of the bucket are only removed under the Enforce drift policy
+func (r *S3BucketReconciler) applyLifecycleConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+
+	if len(s3bkt.Spec.Lifecycle) == 0 {
+		if s3bkt.Spec.DriftPolicy != s3v1alpha1.DriftPolicyEnforce {
+			return nil
+		}
+		// Deleting a configuration that does not exist succeeds
+		if _, err := r.S3svc.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+		}); err != nil {
+			return fmt.Errorf("S3 DeleteBucketLifecycle API call failed: %w", err)
+		}
+		return nil
+	}
+
+	log.Info("Applying lifecycle configuration", "BucketName", s3bkt.Spec.Name, "Rules", len(s3bkt.Spec.Lifecycle))
+	if _, err := r.S3svc.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(s3bkt.Spec.Name),
+		LifecycleConfiguration: buildLifecycleConfiguration(s3bkt.Spec.Lifecycle),
+	}); err != nil {
+		return fmt.Errorf("S3 PutBucketLifecycleConfiguration API call failed: %w", err)
+	}
+	return nil
+}
+
+// applyObjectLockConfiguration sets the default retention of a locked bucket. Buckets that are
+// not locked have no object lock configuration to apply. A default retention set outside the
+// operator is only removed under the Enforce drift policy, otherwise it is returned as drift
+func (r *S3BucketReconciler) applyObjectLockConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	log := logf.FromContext(ctx)
+
+	if !s3bkt.Spec.Locked {
+		return nil, nil
+	}
+
+	current, err := r.S3svc.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == errCodeObjectLockNotFound {
+			// Object lock can only be enabled when the bucket is created
+			log.Info("Object lock is not enabled on the bucket", "BucketName", s3bkt.Spec.Name)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("S3 GetObjectLockConfiguration API call failed: %w", err)
+	}
+
+	var observedDays int64
+	if config := current.ObjectLockConfiguration; config != nil && config.Rule != nil && config.Rule.DefaultRetention != nil {
+		observedDays = aws.Int64Value(config.Rule.DefaultRetention.Days)
+	}
+	if observedDays == int64(s3bkt.Spec.RetentionDays) {
+		return nil, nil
+	}
+	if s3bkt.Spec.RetentionDays == 0 && s3bkt.Spec.DriftPolicy != s3v1alpha1.DriftPolicyEnforce {
+		return []string{fmt.Sprintf("%d days", observedDays)}, nil
+	}
+
+	config := &s3.ObjectLockConfiguration{
+		ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+	}
+	if s3bkt.Spec.RetentionDays > 0 {
+		config.Rule = &s3.ObjectLockRule{
+			DefaultRetention: &s3.DefaultRetention{
+				Mode: aws.String(s3.ObjectLockRetentionModeGovernance),
+				Days: aws.Int64(int64(s3bkt.Spec.RetentionDays)),
+			},
+		}
+	}
+
+	log.Info("Updating default retention", "BucketName", s3bkt.Spec.Name, "RetentionDays", s3bkt.Spec.RetentionDays)
+	if _, err := r.S3svc.PutObjectLockConfigurationWithContext(ctx, &s3.PutObjectLockConfigurationInput{
+		Bucket:                  aws.String(s3bkt.Spec.Name),
+		ObjectLockConfiguration: config,
+	}); err != nil {
+		return nil, fmt.Errorf("S3 PutObjectLockConfiguration API call failed: %w", err)
+	}
+	return nil, nil
+}
+
+// equalStringMaps reports whether two maps hold the same entries, treating nil as empty
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3Bucket lifecycle configuration", func() {
+	It("should convert lifecycle rules to the S3 API representation", func() {
+		config := buildLifecycleConfiguration([]s3v1alpha1.LifecycleRule{{
+			ID:                              "logs",
+			Prefix:                          "logs/",
+			ExpirationDays:                  90,
+			NoncurrentVersionExpirationDays: 7,
+			Transitions:                     []s3v1alpha1.LifecycleTransition{{Days: 30, StorageClass: s3.TransitionStorageClassStandardIa}},
+		}})
+
+		Expect(config.Rules).To(HaveLen(1))
+		rule := config.Rules[0]
+		Expect(aws.StringValue(rule.ID)).To(Equal("logs"))
+		Expect(aws.StringValue(rule.Status)).To(Equal(s3.ExpirationStatusEnabled))
+		Expect(aws.StringValue(rule.Filter.Prefix)).To(Equal("logs/"))
+		Expect(aws.Int64Value(rule.Expiration.Days)).To(Equal(int64(90)))
+		Expect(aws.Int64Value(rule.NoncurrentVersionExpiration.NoncurrentDays)).To(Equal(int64(7)))
+		Expect(rule.Transitions).To(HaveLen(1))
+		Expect(aws.StringValue(rule.Transitions[0].StorageClass)).To(Equal(s3.TransitionStorageClassStandardIa))
+	})
+
+	It("should leave out expirations that are not set", func() {
+		config := buildLifecycleConfiguration([]s3v1alpha1.LifecycleRule{{
+			ID:          "archive",
+			Transitions: []s3v1alpha1.LifecycleTransition{{Days: 0, StorageClass: s3.TransitionStorageClassGlacier}},
+		}})
+
+		Expect(config.Rules[0].Expiration).To(BeNil())
+		Expect(config.Rules[0].NoncurrentVersionExpiration).To(BeNil())
+	})
+
+	It("should compare tags regardless of nil maps", func() {
+		Expect(equalStringMaps(nil, map[string]string{})).To(BeTrue())
+		Expect(equalStringMaps(map[string]string{"team": "a"}, map[string]string{"team": "b"})).To(BeFalse())
+	})
+
+	Context("When the spec declares no tags, lifecycle rules, versioning or retention", func() {
+		var (
+			reconciler *S3BucketReconciler
+			mu         sync.Mutex
+			requests   []string
+		)
+
+		BeforeEach(func() {
+			requests = nil
+			// An S3 endpoint whose bucket has tags and lifecycle rules set outside the operator
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				requests = append(requests, req.Method+" "+req.URL.RawQuery)
+				mu.Unlock()
+				switch {
+				case req.Method == http.MethodGet && req.URL.Query().Has("versioning"):
+					_, _ = w.Write([]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+					return
+				case req.Method == http.MethodGet && req.URL.Query().Has("object-lock"):
+					_, _ = w.Write([]byte(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled>` +
+						`<Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>`))
+					return
+				case req.Method == http.MethodGet:
+					_, _ = w.Write([]byte(`<Tagging><TagSet><Tag><Key>owner</Key><Value>finance</Value></Tag></TagSet></Tagging>`))
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			DeferCleanup(server.Close)
+			sess, err := session.NewSession(&aws.Config{
+				Endpoint:         aws.String(server.URL),
+				Region:           aws.String("us-east-1"),
+				S3ForcePathStyle: aws.Bool(true),
+				Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			reconciler = &S3BucketReconciler{S3svc: s3.New(sess)}
+		})
+
+		It("should keep the remote settings under the Report drift policy", func() {
+			s3bkt := &s3v1alpha1.S3Bucket{Spec: s3v1alpha1.S3BucketSpec{Name: "kept", DriftPolicy: s3v1alpha1.DriftPolicyReport}}
+			Expect(reconciler.applyTagging(context.Background(), s3bkt)).To(Succeed())
+			Expect(reconciler.applyLifecycleConfiguration(context.Background(), s3bkt)).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("should remove the remote settings under the Enforce drift policy", func() {
+			s3bkt := &s3v1alpha1.S3Bucket{Spec: s3v1alpha1.S3BucketSpec{Name: "enforced", DriftPolicy: s3v1alpha1.DriftPolicyEnforce}}
+			Expect(reconciler.applyTagging(context.Background(), s3bkt)).To(Succeed())
+			Expect(reconciler.applyLifecycleConfiguration(context.Background(), s3bkt)).To(Succeed())
+			Expect(requests).To(ContainElements("DELETE tagging=", "DELETE lifecycle="))
+		})
+
+		It("should report versioning and default retention set outside the operator", func() {
+			s3bkt := &s3v1alpha1.S3Bucket{Spec: s3v1alpha1.S3BucketSpec{Name: "kept"}}
+			drift, err := reconciler.applyVersioningConfiguration(context.Background(), s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(Equal([]string{"Enabled"}))
+
+			s3bkt.Spec.Locked = true
+			drift, err = reconciler.applyObjectLockConfiguration(context.Background(), s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(Equal([]string{"30 days"}))
+			Expect(requests).To(Equal([]string{"GET versioning=", "GET object-lock="}))
+		})
+
+		It("should suspend versioning and remove the default retention under the Enforce drift policy", func() {
+			s3bkt := &s3v1alpha1.S3Bucket{Spec: s3v1alpha1.S3BucketSpec{Name: "enforced", DriftPolicy: s3v1alpha1.DriftPolicyEnforce}}
+			drift, err := reconciler.applyVersioningConfiguration(context.Background(), s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+
+			s3bkt.Spec.Locked = true
+			drift, err = reconciler.applyObjectLockConfiguration(context.Background(), s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+			Expect(requests).To(ContainElements("PUT versioning=", "PUT object-lock="))
+		})
+	})
+})
@@ -0,0 +1,247 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+const (
+	// credentialsExpiryAnnotation records when the credentials issued in the credentials Secret expire
+	credentialsExpiryAnnotation = "s3.acme.io/credentials-expiry"
+	// credentialsRenewBefore is how long before they expire issued credentials are renewed
+	credentialsRenewBefore = 24 * time.Hour
+)
+
+// bucketStates remembers the state last reported for each managed bucket, so its ConfigMap and
+// Secrets can be repaired without calling the provider
+type bucketStates struct {
+	mu     sync.Mutex
+	states map[types.NamespacedName]observedState
+}
+
+// observedState is the state a bucket manager reported for a generation of a bucket
+type observedState struct {
+	generation int64
+	manager    provider.BucketManager
+	state      *provider.BucketState
+}
+
+func newBucketStates() *bucketStates {
+	return &bucketStates{states: map[types.NamespacedName]observedState{}}
+}
+
+// load returns the state manager last reported for the current generation of the bucket, or nil
+func (s *bucketStates) load(s3bkt *s3v1alpha1.S3Bucket, manager provider.BucketManager) *provider.BucketState {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	observed, ok := s.states[client.ObjectKeyFromObject(s3bkt)]
+	if !ok || observed.generation != s3bkt.Generation || observed.manager != manager {
+		return nil
+	}
+	return observed.state
+}
+
+// store records the state manager reported for the current generation of the bucket
+func (s *bucketStates) store(s3bkt *s3v1alpha1.S3Bucket, manager provider.BucketManager, state *provider.BucketState) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[client.ObjectKeyFromObject(s3bkt)] = observedState{generation: s3bkt.Generation, manager: manager, state: state}
+}
+
+// forget drops the state of a deleted bucket
+func (s *bucketStates) forget(s3bkt *s3v1alpha1.S3Bucket) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, client.ObjectKeyFromObject(s3bkt))
+}
+
+// ensureManagedBucket creates or updates the bucket through the bucket manager of its provider,
+// then publishes the state it reports
+func (r *S3BucketReconciler) ensureManagedBucket(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	state, err := r.manager.Ensure(ctx, s3bkt)
+	if err != nil {
+		return fmt.Errorf("failed to ensure bucket: %w", err)
+	}
+	r.states.store(s3bkt, r.manager, state)
+	return r.publishManagedBucket(ctx, s3bkt, state)
+}
+
+// publishManagedBucket publishes the state of a managed bucket in its ConfigMap and Secrets and
+// records it in the in-memory status
+func (r *S3BucketReconciler) publishManagedBucket(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, state *provider.BucketState) error {
+	setFeaturesCondition(s3bkt, state.Unsupported)
+
+	s3bkt.Status.RequestPayer = s3.PayerBucketOwner
+	if s3bkt.Spec.RequesterPays && !slices.Contains(state.Unsupported, "requesterPays") {
+		s3bkt.Status.RequestPayer = s3.PayerRequester
+	}
+
+	data := map[string]string{
+		"BucketName": s3bkt.Spec.Name,
+		"Region":     state.Location,
+		"Locked":     fmt.Sprintf("%t", s3bkt.Spec.Locked),
+		"location":   state.URL,
+	}
+	for key, value := range configurationData(s3bkt) {
+		data[key] = value
+	}
+	for key, value := range state.ConnectionDetails {
+		data[key] = value
+	}
+
+	// Connection details templates and the binding Secret may render the credentials
+	if state.Secret != nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf(credentialsSecretName, s3bkt.Name),
+				Namespace: s3bkt.Namespace,
+			},
+		}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			if err := r.issueCredentials(s3bkt, secret, state.Secret); err != nil {
+				return err
+			}
+			return controllerutil.SetControllerReference(s3bkt, secret, r.Scheme)
+		}); err != nil {
+			return fmt.Errorf("failed to write credentials Secret: %w", err)
+		}
+	}
+
+	facts := connectionFacts{
+		BucketName: s3bkt.Spec.Name,
+		Region:     state.Location,
+		Endpoint:   data["Endpoint"],
+		URL:        state.URL,
+	}
+	if err := r.ensureBindingSecret(ctx, s3bkt, facts); err != nil {
+		return err
+	}
+	details, err := r.publishConnectionDetails(ctx, s3bkt, facts)
+	if err != nil {
+		return err
+	}
+	return r.writeBucketConfigMap(ctx, s3bkt, data, nil, details)
+}
+
+// issueCredentials sets the data of the credentials Secret to the connection details of the
+// provider, with the credentials issued for the bucket when its manager is a CredentialIssuer.
+// Issued credentials are kept until they are due for renewal
+func (r *S3BucketReconciler) issueCredentials(s3bkt *s3v1alpha1.S3Bucket, secret *corev1.Secret, details map[string][]byte) error {
+	issuer, ok := r.manager.(provider.CredentialIssuer)
+	if !ok {
+		secret.Data = details
+		return nil
+	}
+
+	data := map[string][]byte{}
+	if renewAfter(secret) > 0 {
+		maps.Copy(data, secret.Data)
+	} else {
+		credentials, expiry, err := issuer.IssueCredentials(s3bkt, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to issue bucket credentials: %w", err)
+		}
+		maps.Copy(data, credentials)
+		metav1.SetMetaDataAnnotation(&secret.ObjectMeta, credentialsExpiryAnnotation, expiry.UTC().Format(time.RFC3339))
+	}
+	maps.Copy(data, details)
+	secret.Data = data
+	return nil
+}
+
+// credentialsRenewal returns how long until the issued credentials of the bucket are due for
+// renewal, or zero when its manager issues none
+func (r *S3BucketReconciler) credentialsRenewal(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (time.Duration, error) {
+	if _, ok := r.manager.(provider.CredentialIssuer); !ok {
+		return 0, nil
+	}
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf(credentialsSecretName, s3bkt.Name), Namespace: s3bkt.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get credentials Secret: %w", err)
+	}
+	return max(renewAfter(secret), time.Second), nil
+}
+
+// renewAfter returns how long until the credentials issued in secret are due for renewal; zero
+// or less when they are or when the Secret has none
+func renewAfter(secret *corev1.Secret) time.Duration {
+	expiry, err := time.Parse(time.RFC3339, secret.Annotations[credentialsExpiryAnnotation])
+	if err != nil {
+		return 0
+	}
+	return time.Until(expiry) - credentialsRenewBefore
+}
+
+// deleteManagedBucket deletes the bucket through the bucket manager of its provider, then its
+// ConfigMap and credentials Secret
+func (r *S3BucketReconciler) deleteManagedBucket(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+
+	if err := r.manager.Delete(ctx, s3bkt); err != nil {
+		return fmt.Errorf("failed to delete bucket: %w", err)
+	}
+	r.states.forget(s3bkt)
+
+	// Delete the ConfigMap (best effort - don't fail if it doesn't exist)
+	if err := r.deleteBucketConfigMap(ctx, s3bkt); err != nil {
+		log.Error(err, "Failed to delete ConfigMap, but bucket is deleted", "BucketName", s3bkt.Spec.Name)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(credentialsSecretName, s3bkt.Name),
+			Namespace: s3bkt.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete credentials Secret: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/gcs"
+)
+
+// fakeBucketManager records the buckets it manages in memory
+type fakeBucketManager struct {
+	buckets     map[string]bool
+	unsupported []string
+	details     map[string]string
+	secret      map[string][]byte
+	err         error
+	ensured     int
+}
+
+func (m *fakeBucketManager) Ensure(_ context.Context, s3bkt *s3v1alpha1.S3Bucket) (*provider.BucketState, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.buckets[s3bkt.Spec.Name] = true
+	m.ensured++
+	return &provider.BucketState{
+		Location:          "EUROPE-WEST1",
+		URL:               "https://storage.example.com/" + s3bkt.Spec.Name,
+		Unsupported:       m.unsupported,
+		ConnectionDetails: m.details,
+		Secret:            m.secret,
+	}, nil
+}
+
+func (m *fakeBucketManager) Delete(_ context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	delete(m.buckets, s3bkt.Spec.Name)
+	return nil
+}
+
+// fakeCredentialIssuer is a fakeBucketManager issuing numbered credentials valid for a week
+type fakeCredentialIssuer struct {
+	*fakeBucketManager
+	issued int
+}
+
+func (m *fakeCredentialIssuer) IssueCredentials(_ *s3v1alpha1.S3Bucket, now time.Time) (map[string][]byte, time.Time, error) {
+	m.issued++
+	return map[string][]byte{"AZURE_STORAGE_SAS_TOKEN": []byte(fmt.Sprintf("sig-%d", m.issued))}, now.Add(7 * 24 * time.Hour), nil
+}
+
+var _ = Describe("S3Bucket Controller", func() {
+	Context("When the provider has a bucket manager", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			manager    *fakeBucketManager
+			reconciler *S3BucketReconciler
+			request    reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			s3bkt := &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+				Spec: s3v1alpha1.S3BucketSpec{
+					Name:          "my-gcs-bucket",
+					Region:        "europe-west1",
+					RequesterPays: true,
+					Acceleration:  true,
+				},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+				WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+			manager = &fakeBucketManager{buckets: map[string]bool{}, unsupported: []string{"acceleration"}}
+			reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, manager: manager, states: newBucketStates()}
+			request = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(s3bkt)}
+		})
+
+		getBucket := func() *s3v1alpha1.S3Bucket {
+			s3bkt := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, request.NamespacedName, s3bkt)).To(Succeed())
+			return s3bkt
+		}
+
+		reconcileUntilCreated := func() {
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, request)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(getBucket().Status.State).To(Equal(s3v1alpha1.CREATED_STATE))
+		}
+
+		It("should create the bucket and write the ConfigMap keys", func() {
+			reconcileUntilCreated()
+			Expect(manager.buckets).To(HaveKey("my-gcs-bucket"))
+
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}, cm)).To(Succeed())
+			Expect(cm.Data).To(Equal(map[string]string{
+				"BucketName":    "my-gcs-bucket",
+				"Region":        "EUROPE-WEST1",
+				"Locked":        "false",
+				"location":      "https://storage.example.com/my-gcs-bucket",
+				"RequesterPays": "true",
+			}))
+			Expect(cm.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("should repair the ConfigMap without changing the bucket", func() {
+			reconcileUntilCreated()
+			Expect(manager.ensured).To(Equal(1))
+
+			cmKey := client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(c.Delete(ctx, cm)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveKeyWithValue("Region", "EUROPE-WEST1"))
+			Expect(manager.ensured).To(Equal(1))
+
+			By("ensuring the bucket once when no state was recorded, as after a restart")
+			reconciler.states = newBucketStates()
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.ensured).To(Equal(2))
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.ensured).To(Equal(2))
+		})
+
+		It("should publish the connection details of the provider", func() {
+			manager.details = map[string]string{"AccountURL": "https://account.blob.core.windows.net"}
+			manager.secret = map[string][]byte{"AZURE_STORAGE_CONTAINER": []byte("my-gcs-bucket")}
+			reconcileUntilCreated()
+
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveKeyWithValue("AccountURL", "https://account.blob.core.windows.net"))
+
+			secret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "data-s3-credentials", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("AZURE_STORAGE_CONTAINER", []byte("my-gcs-bucket")))
+
+			Expect(c.Delete(ctx, getBucket())).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			err = c.Get(ctx, client.ObjectKey{Name: "data-s3-credentials", Namespace: "default"}, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should publish issued credentials and renew them before they expire", func() {
+			manager.secret = map[string][]byte{"AZURE_STORAGE_CONTAINER": []byte("my-gcs-bucket")}
+			issuer := &fakeCredentialIssuer{fakeBucketManager: manager}
+			reconciler.manager = issuer
+			reconcileUntilCreated()
+
+			secret := &corev1.Secret{}
+			secretKey := client.ObjectKey{Name: "data-s3-credentials", Namespace: "default"}
+			Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("AZURE_STORAGE_SAS_TOKEN", []byte("sig-1")))
+			Expect(secret.Data).To(HaveKeyWithValue("AZURE_STORAGE_CONTAINER", []byte("my-gcs-bucket")))
+			Expect(secret.Annotations).To(HaveKey(credentialsExpiryAnnotation))
+
+			By("keeping the credentials until they are due for renewal")
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 6*24*time.Hour, time.Minute))
+			Expect(issuer.issued).To(Equal(1))
+
+			By("renewing them a day before they expire")
+			Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+			secret.Annotations[credentialsExpiryAnnotation] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			Expect(c.Update(ctx, secret)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("AZURE_STORAGE_SAS_TOKEN", []byte("sig-2")))
+		})
+
+		It("should report the fields the provider does not implement", func() {
+			reconcileUntilCreated()
+
+			condition := meta.FindStatusCondition(getBucket().Status.Conditions, s3v1alpha1.ConditionFeaturesSupported)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(ContainSubstring("acceleration"))
+		})
+
+		It("should back off and retry a failed creation", func() {
+			manager.err = &gcs.APIError{StatusCode: 503, Message: "backend unavailable"}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(retryBaseDelay))
+
+			s3bkt := getBucket()
+			Expect(s3bkt.Status.State).To(Equal(s3v1alpha1.ERROR_STATE))
+			Expect(s3bkt.Status.Retry).NotTo(BeNil())
+			Expect(s3bkt.Status.Retry.Operation).To(Equal("create"))
+			Expect(s3bkt.Status.Retry.Count).To(BeEquivalentTo(1))
+			Expect(s3bkt.Status.Retry.Reason).To(Equal(s3v1alpha1.RetryReasonRetryable))
+			Expect(s3bkt.Status.Retry.Message).To(ContainSubstring("backend unavailable"))
+
+			By("waiting for the backoff")
+			result, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(And(BeNumerically(">", 0), BeNumerically("<=", retryBaseDelay)))
+			Expect(getBucket().Status.Retry.Count).To(BeEquivalentTo(1))
+
+			expireBackoff := func() {
+				s3bkt := getBucket()
+				expired := metav1.NewTime(time.Now().Add(-time.Second))
+				s3bkt.Status.Retry.NextRetryTime = &expired
+				Expect(c.Status().Update(ctx, s3bkt)).To(Succeed())
+			}
+
+			By("retrying from the ERROR state with a longer backoff")
+			expireBackoff()
+			result, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(2 * retryBaseDelay))
+			Expect(getBucket().Status.Retry.Count).To(BeEquivalentTo(2))
+
+			By("clearing the retries once the creation succeeds")
+			manager.err = nil
+			expireBackoff()
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			s3bkt = getBucket()
+			Expect(s3bkt.Status.State).To(Equal(s3v1alpha1.CREATED_STATE))
+			Expect(s3bkt.Status.Retry).To(BeNil())
+			Expect(manager.buckets).To(HaveKey("my-gcs-bucket"))
+		})
+
+		It("should make no remote changes while paused", func() {
+			setPaused := func(paused bool) {
+				s3bkt := getBucket()
+				if paused {
+					s3bkt.Annotations = map[string]string{s3v1alpha1.PausedAnnotation: "true"}
+				} else {
+					delete(s3bkt.Annotations, s3v1alpha1.PausedAnnotation)
+				}
+				Expect(c.Update(ctx, s3bkt)).To(Succeed())
+			}
+			reconcileUntilCreated()
+			setPaused(true)
+
+			By("keeping the bucket when the S3Bucket is deleted")
+			Expect(c.Delete(ctx, getBucket())).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.buckets).To(HaveKey("my-gcs-bucket"))
+			s3bkt := getBucket()
+			Expect(s3bkt.Finalizers).To(ContainElement(s3BucketFinalizer))
+			condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionPaused)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("resuming once the annotation is removed")
+			setPaused(false)
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.buckets).To(BeEmpty())
+			err = c.Get(ctx, request.NamespacedName, &s3v1alpha1.S3Bucket{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report when a paused bucket resumes", func() {
+			s3bkt := getBucket()
+			s3bkt.Annotations = map[string]string{s3v1alpha1.PausedAnnotation: "true"}
+			Expect(c.Update(ctx, s3bkt)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.buckets).To(BeEmpty())
+			Expect(getBucket().Finalizers).To(BeEmpty())
+
+			s3bkt = getBucket()
+			s3bkt.Annotations = nil
+			Expect(c.Update(ctx, s3bkt)).To(Succeed())
+			reconcileUntilCreated()
+			condition := meta.FindStatusCondition(getBucket().Status.Conditions, s3v1alpha1.ConditionPaused)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Resumed"))
+		})
+
+		It("should delete the bucket and its ConfigMap", func() {
+			reconcileUntilCreated()
+
+			Expect(c.Delete(ctx, getBucket())).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manager.buckets).To(BeEmpty())
+			err = c.Get(ctx, client.ObjectKey{Name: "data-s3-cm", Namespace: "default"}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
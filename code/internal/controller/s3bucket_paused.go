@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+// isPaused reports whether the bucket carries the paused annotation
+func isPaused(s3bkt *s3v1alpha1.S3Bucket) bool {
+	return s3bkt.Annotations[s3v1alpha1.PausedAnnotation] == "true"
+}
+
+// setPausedCondition reports whether the reconciliation of the bucket is paused. Buckets that
+// were never paused get no condition.
+func (r *S3BucketReconciler) setPausedCondition(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, paused bool) error {
+	current := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionPaused)
+	condition := metav1.Condition{
+		Type:               s3v1alpha1.ConditionPaused,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: s3bkt.Generation,
+		Reason:             "Annotated",
+		Message:            "Reconciliation is paused by the " + s3v1alpha1.PausedAnnotation + " annotation",
+	}
+	if !paused {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Resumed"
+		condition.Message = "Reconciliation resumed after the " + s3v1alpha1.PausedAnnotation + " annotation was removed"
+	} else if current != nil && current.Status == metav1.ConditionTrue && current.ObservedGeneration == s3bkt.Generation {
+		return nil
+	}
+
+	if err := r.updateBucketStatus(ctx, s3bkt, "", func(status *s3v1alpha1.S3BucketStatus) {
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}); err != nil {
+		return err
+	}
+	// Later status writes copy the conditions of s3bkt
+	meta.SetStatusCondition(&s3bkt.Status.Conditions, condition)
+
+	if paused && (current == nil || current.Status != metav1.ConditionTrue) {
+		r.Recorder.Normal(s3bkt, recorder.ReasonPaused, "Paused reconciliation of bucket %s", s3bkt.Spec.Name)
+	} else if !paused {
+		r.Recorder.Normal(s3bkt, recorder.ReasonResumed, "Resumed reconciliation of bucket %s", s3bkt.Spec.Name)
+	}
+	return nil
+}
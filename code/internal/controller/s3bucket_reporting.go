@@ -0,0 +1,426 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+// Kinds of bucket configurations, used in drift reports
+const (
+	inventoryKind          = "inventory"
+	analyticsKind          = "analytics"
+	intelligentTieringKind = "intelligentTiering"
+	versioningKind         = "versioning"
+	retentionKind          = "retention"
+)
+
+// bucketARN returns the ARN of an S3 bucket in the partition of its region, e.g. aws-cn in China
+func bucketARN(bucket, region string) string {
+	partition := endpoints.AwsPartitionID
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		partition = p.ID()
+	}
+	return "arn:" + partition + ":s3:::" + bucket
+}
+
+// resolveBucketARN returns the ARN of the bucket managed by the referenced S3Bucket resource. Like
+// an S3BucketAccess, a bucket in another namespace must allow the namespace of s3bkt
+func (r *S3BucketReconciler) resolveBucketARN(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, ref s3v1alpha1.BucketReference) (string, error) {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = s3bkt.Namespace
+	}
+
+	target := &s3v1alpha1.S3Bucket{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, target); err != nil {
+		return "", fmt.Errorf("failed to get destination S3Bucket %s/%s: %w", namespace, ref.Name, err)
+	}
+	if !namespaceAllowed(target, s3bkt.Namespace) {
+		return "", fmt.Errorf("destination S3Bucket %s/%s does not allow access from namespace %s", namespace, ref.Name, s3bkt.Namespace)
+	}
+	if target.Status.State != s3v1alpha1.CREATED_STATE {
+		return "", fmt.Errorf("destination S3Bucket %s/%s is not in CREATED state", namespace, ref.Name)
+	}
+
+	return bucketARN(target.Spec.Name, r.bucketRegion(target)), nil
+}
+
+// staleIDs returns the sorted remote IDs that are not declared in the spec
+func staleIDs(desired map[string]bool, remote []string) []string {
+	var stale []string
+	for _, id := range remote {
+		if !desired[id] {
+			stale = append(stale, id)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// removeOrReport deletes stale configurations when the drift policy is Enforce,
+// otherwise it returns them so they can be reported as drift
+func (r *S3BucketReconciler) removeOrReport(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, kind string, stale []string, remove func(id string) error) ([]string, error) {
+	log := logf.FromContext(ctx)
+
+	if s3bkt.Spec.DriftPolicy != s3v1alpha1.DriftPolicyEnforce {
+		return stale, nil
+	}
+
+	for _, id := range stale {
+		log.Info("Removing unmanaged bucket configuration", "BucketName", s3bkt.Spec.Name, "Kind", kind, "ID", id)
+		if err := remove(id); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// applyInventoryConfigurations reconciles the S3 Inventory configurations by ID
+// and returns the IDs of unmanaged remote configurations
+func (r *S3BucketReconciler) applyInventoryConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	for _, inventory := range s3bkt.Spec.Inventory {
+		destination, err := r.resolveBucketARN(ctx, s3bkt, inventory.Destination.BucketRef)
+		if err != nil {
+			return nil, err
+		}
+
+		config := &s3.InventoryConfiguration{
+			Id:                     aws.String(inventory.ID),
+			IsEnabled:              aws.Bool(true),
+			IncludedObjectVersions: aws.String(defaultString(inventory.IncludedObjectVersions, s3.InventoryIncludedObjectVersionsCurrent)),
+			OptionalFields:         aws.StringSlice(inventory.OptionalFields),
+			Schedule: &s3.InventorySchedule{
+				Frequency: aws.String(defaultString(inventory.Frequency, s3.InventoryFrequencyDaily)),
+			},
+			Destination: &s3.InventoryDestination{
+				S3BucketDestination: &s3.InventoryS3BucketDestination{
+					Bucket: aws.String(destination),
+					Format: aws.String(defaultString(inventory.Destination.Format, s3.InventoryFormatCsv)),
+					Prefix: optionalString(inventory.Destination.Prefix),
+				},
+			},
+		}
+		if inventory.Prefix != "" {
+			config.Filter = &s3.InventoryFilter{Prefix: aws.String(inventory.Prefix)}
+		}
+
+		if _, err := r.S3svc.PutBucketInventoryConfigurationWithContext(ctx, &s3.PutBucketInventoryConfigurationInput{
+			Bucket:                 aws.String(s3bkt.Spec.Name),
+			Id:                     aws.String(inventory.ID),
+			InventoryConfiguration: config,
+		}); err != nil {
+			return nil, fmt.Errorf("S3 PutBucketInventoryConfiguration API call failed for %q: %w", inventory.ID, err)
+		}
+	}
+	return r.checkInventoryConfigurations(ctx, s3bkt)
+}
+
+// checkInventoryConfigurations returns the IDs of remote S3 Inventory configurations that are
+// not declared in the spec, removing them under the Enforce drift policy
+func (r *S3BucketReconciler) checkInventoryConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	desired := map[string]bool{}
+	for _, inventory := range s3bkt.Spec.Inventory {
+		desired[inventory.ID] = true
+	}
+
+	var remote []string
+	input := &s3.ListBucketInventoryConfigurationsInput{Bucket: aws.String(s3bkt.Spec.Name)}
+	for {
+		output, err := r.S3svc.ListBucketInventoryConfigurationsWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("S3 ListBucketInventoryConfigurations API call failed: %w", err)
+		}
+		for _, config := range output.InventoryConfigurationList {
+			remote = append(remote, aws.StringValue(config.Id))
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = output.NextContinuationToken
+	}
+
+	return r.removeOrReport(ctx, s3bkt, inventoryKind, staleIDs(desired, remote), func(id string) error {
+		_, err := r.S3svc.DeleteBucketInventoryConfigurationWithContext(ctx, &s3.DeleteBucketInventoryConfigurationInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+			Id:     aws.String(id),
+		})
+		if err != nil {
+			return fmt.Errorf("S3 DeleteBucketInventoryConfiguration API call failed for %q: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// applyAnalyticsConfigurations reconciles the storage class analysis configurations by ID
+// and returns the IDs of unmanaged remote configurations
+func (r *S3BucketReconciler) applyAnalyticsConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	for _, analytics := range s3bkt.Spec.Analytics {
+		config := &s3.AnalyticsConfiguration{
+			Id:                   aws.String(analytics.ID),
+			StorageClassAnalysis: &s3.StorageClassAnalysis{},
+		}
+		if analytics.Prefix != "" {
+			config.Filter = &s3.AnalyticsFilter{Prefix: aws.String(analytics.Prefix)}
+		}
+		if analytics.Export != nil {
+			destination, err := r.resolveBucketARN(ctx, s3bkt, analytics.Export.BucketRef)
+			if err != nil {
+				return nil, err
+			}
+			config.StorageClassAnalysis.DataExport = &s3.StorageClassAnalysisDataExport{
+				OutputSchemaVersion: aws.String(s3.StorageClassAnalysisSchemaVersionV1),
+				Destination: &s3.AnalyticsExportDestination{
+					S3BucketDestination: &s3.AnalyticsS3BucketDestination{
+						Bucket: aws.String(destination),
+						Format: aws.String(s3.AnalyticsS3ExportFileFormatCsv),
+						Prefix: optionalString(analytics.Export.Prefix),
+					},
+				},
+			}
+		}
+
+		if _, err := r.S3svc.PutBucketAnalyticsConfigurationWithContext(ctx, &s3.PutBucketAnalyticsConfigurationInput{
+			Bucket:                 aws.String(s3bkt.Spec.Name),
+			Id:                     aws.String(analytics.ID),
+			AnalyticsConfiguration: config,
+		}); err != nil {
+			return nil, fmt.Errorf("S3 PutBucketAnalyticsConfiguration API call failed for %q: %w", analytics.ID, err)
+		}
+	}
+	return r.checkAnalyticsConfigurations(ctx, s3bkt)
+}
+
+// checkAnalyticsConfigurations returns the IDs of remote storage class analysis configurations
+// that are not declared in the spec, removing them under the Enforce drift policy
+func (r *S3BucketReconciler) checkAnalyticsConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	desired := map[string]bool{}
+	for _, analytics := range s3bkt.Spec.Analytics {
+		desired[analytics.ID] = true
+	}
+
+	var remote []string
+	input := &s3.ListBucketAnalyticsConfigurationsInput{Bucket: aws.String(s3bkt.Spec.Name)}
+	for {
+		output, err := r.S3svc.ListBucketAnalyticsConfigurationsWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("S3 ListBucketAnalyticsConfigurations API call failed: %w", err)
+		}
+		for _, config := range output.AnalyticsConfigurationList {
+			remote = append(remote, aws.StringValue(config.Id))
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = output.NextContinuationToken
+	}
+
+	return r.removeOrReport(ctx, s3bkt, analyticsKind, staleIDs(desired, remote), func(id string) error {
+		_, err := r.S3svc.DeleteBucketAnalyticsConfigurationWithContext(ctx, &s3.DeleteBucketAnalyticsConfigurationInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+			Id:     aws.String(id),
+		})
+		if err != nil {
+			return fmt.Errorf("S3 DeleteBucketAnalyticsConfiguration API call failed for %q: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// applyIntelligentTieringConfigurations reconciles the intelligent-tiering configurations by ID
+// and returns the IDs of unmanaged remote configurations
+func (r *S3BucketReconciler) applyIntelligentTieringConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	for _, tiering := range s3bkt.Spec.IntelligentTiering {
+		config := &s3.IntelligentTieringConfiguration{
+			Id:     aws.String(tiering.ID),
+			Status: aws.String(s3.IntelligentTieringStatusEnabled),
+		}
+		if tiering.Prefix != "" {
+			config.Filter = &s3.IntelligentTieringFilter{Prefix: aws.String(tiering.Prefix)}
+		}
+		if tiering.ArchiveAccessDays > 0 {
+			config.Tierings = append(config.Tierings, &s3.Tiering{
+				AccessTier: aws.String(s3.IntelligentTieringAccessTierArchiveAccess),
+				Days:       aws.Int64(tiering.ArchiveAccessDays),
+			})
+		}
+		if tiering.DeepArchiveAccessDays > 0 {
+			config.Tierings = append(config.Tierings, &s3.Tiering{
+				AccessTier: aws.String(s3.IntelligentTieringAccessTierDeepArchiveAccess),
+				Days:       aws.Int64(tiering.DeepArchiveAccessDays),
+			})
+		}
+		if len(config.Tierings) == 0 {
+			return nil, fmt.Errorf("intelligent-tiering configuration %q needs archiveAccessDays or deepArchiveAccessDays", tiering.ID)
+		}
+
+		if _, err := r.S3svc.PutBucketIntelligentTieringConfigurationWithContext(ctx, &s3.PutBucketIntelligentTieringConfigurationInput{
+			Bucket:                          aws.String(s3bkt.Spec.Name),
+			Id:                              aws.String(tiering.ID),
+			IntelligentTieringConfiguration: config,
+		}); err != nil {
+			return nil, fmt.Errorf("S3 PutBucketIntelligentTieringConfiguration API call failed for %q: %w", tiering.ID, err)
+		}
+	}
+	return r.checkIntelligentTieringConfigurations(ctx, s3bkt)
+}
+
+// checkIntelligentTieringConfigurations returns the IDs of remote intelligent-tiering
+// configurations that are not declared in the spec, removing them under the Enforce drift policy
+func (r *S3BucketReconciler) checkIntelligentTieringConfigurations(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) ([]string, error) {
+	desired := map[string]bool{}
+	for _, tiering := range s3bkt.Spec.IntelligentTiering {
+		desired[tiering.ID] = true
+	}
+
+	var remote []string
+	input := &s3.ListBucketIntelligentTieringConfigurationsInput{Bucket: aws.String(s3bkt.Spec.Name)}
+	for {
+		output, err := r.S3svc.ListBucketIntelligentTieringConfigurationsWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("S3 ListBucketIntelligentTieringConfigurations API call failed: %w", err)
+		}
+		for _, config := range output.IntelligentTieringConfigurationList {
+			remote = append(remote, aws.StringValue(config.Id))
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = output.NextContinuationToken
+	}
+
+	return r.removeOrReport(ctx, s3bkt, intelligentTieringKind, staleIDs(desired, remote), func(id string) error {
+		_, err := r.S3svc.DeleteBucketIntelligentTieringConfigurationWithContext(ctx, &s3.DeleteBucketIntelligentTieringConfigurationInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+			Id:     aws.String(id),
+		})
+		if err != nil {
+			return fmt.Errorf("S3 DeleteBucketIntelligentTieringConfiguration API call failed for %q: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// refreshDrift checks the bucket for unmanaged configurations when the last check is older than
+// the drift interval, and returns when to check it next. A zero interval disables the check
+func (r *S3BucketReconciler) refreshDrift(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (time.Duration, error) {
+	if r.DriftInterval <= 0 || r.manager != nil {
+		return 0, nil
+	}
+	if checked := s3bkt.Status.LastDriftCheckTime; checked != nil {
+		if age := time.Since(checked.Time); age < r.DriftInterval {
+			return r.DriftInterval - age, nil
+		}
+	}
+
+	unmanaged := map[string][]string{}
+	for kind, check := range map[string]func(context.Context, *s3v1alpha1.S3Bucket) ([]string, error){
+		inventoryKind:          r.checkInventoryConfigurations,
+		analyticsKind:          r.checkAnalyticsConfigurations,
+		intelligentTieringKind: r.checkIntelligentTieringConfigurations,
+		versioningKind:         r.applyVersioningConfiguration,
+		retentionKind:          r.applyObjectLockConfiguration,
+	} {
+		ids, err := check(ctx, s3bkt)
+		// Backends lacking the API have no configurations of this kind
+		if err != nil && !isUnsupported(err) {
+			return r.DriftInterval, fmt.Errorf("failed to check %s configurations: %w", kind, err)
+		}
+		unmanaged[kind] = ids
+	}
+	r.recordDrift(s3bkt, unmanaged)
+	drifted := *meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionDrifted)
+
+	now := metav1.Now()
+	if err := r.updateBucketStatus(ctx, s3bkt, "", func(status *s3v1alpha1.S3BucketStatus) {
+		status.LastDriftCheckTime = &now
+		meta.SetStatusCondition(&status.Conditions, drifted)
+	}); err != nil {
+		return 0, fmt.Errorf("failed to record drift check: %w", err)
+	}
+	s3bkt.Status.LastDriftCheckTime = &now
+	return r.DriftInterval, nil
+}
+
+// recordDrift sets the Drifted condition and emits a warning when the bucket starts drifting
+func (r *S3BucketReconciler) recordDrift(s3bkt *s3v1alpha1.S3Bucket, unmanaged map[string][]string) {
+	if setDriftCondition(s3bkt, unmanaged) {
+		drifted := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionDrifted)
+		r.Recorder.Warning(s3bkt, recorder.ReasonDriftDetected, "Bucket drifted from its spec", errors.New(drifted.Message))
+	}
+}
+
+// setDriftCondition records the unmanaged remote configurations, keyed by kind, in the Drifted
+// condition, and reports whether the condition turned True
+func setDriftCondition(s3bkt *s3v1alpha1.S3Bucket, unmanaged map[string][]string) bool {
+	wasDrifted := meta.IsStatusConditionTrue(s3bkt.Status.Conditions, s3v1alpha1.ConditionDrifted)
+	kinds := make([]string, 0, len(unmanaged))
+	for kind, ids := range unmanaged {
+		if len(ids) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+
+	if len(kinds) == 0 {
+		meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionDrifted,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: s3bkt.Generation,
+			Reason:             "InSync",
+			Message:            "All remote configurations are declared in the spec",
+		})
+		return false
+	}
+
+	details := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		details = append(details, fmt.Sprintf("%s: %s", kind, strings.Join(unmanaged[kind], ", ")))
+	}
+	meta.SetStatusCondition(&s3bkt.Status.Conditions, metav1.Condition{
+		Type:               s3v1alpha1.ConditionDrifted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: s3bkt.Generation,
+		Reason:             "UnmanagedConfigurations",
+		Message:            "Remote configurations not declared in the spec: " + strings.Join(details, "; "),
+	})
+	return !wasDrifted
+}
+
+// defaultString returns value, or fallback when value is empty
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+var _ = Describe("S3Bucket inventory, analytics and intelligent-tiering", func() {
+	It("should find remote configurations that are not declared in the spec", func() {
+		desired := map[string]bool{"daily": true}
+		Expect(staleIDs(desired, []string{"weekly", "daily", "adhoc"})).To(Equal([]string{"adhoc", "weekly"}))
+		Expect(staleIDs(desired, []string{"daily"})).To(BeEmpty())
+	})
+
+	It("should build bucket ARNs in the partition of the region", func() {
+		Expect(bucketARN("data", "eu-west-1")).To(Equal("arn:aws:s3:::data"))
+		Expect(bucketARN("data", "cn-north-1")).To(Equal("arn:aws-cn:s3:::data"))
+		Expect(bucketARN("data", "us-gov-west-1")).To(Equal("arn:aws-us-gov:s3:::data"))
+		Expect(bucketARN("data", "")).To(Equal("arn:aws:s3:::data"))
+	})
+
+	It("should only resolve destination buckets that allow the namespace", func() {
+		scheme := runtime.NewScheme()
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+		destination := &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "finance"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: "finance-reports", Region: "cn-north-1", AllowedAccessNamespaces: []string{"analytics"}},
+			Status:     s3v1alpha1.S3BucketStatus{State: s3v1alpha1.CREATED_STATE},
+		}
+		reconciler := &S3BucketReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(destination).Build()}
+		ref := s3v1alpha1.BucketReference{Name: "reports", Namespace: "finance"}
+
+		allowed := &s3v1alpha1.S3Bucket{ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "analytics"}}
+		Expect(reconciler.resolveBucketARN(context.Background(), allowed, ref)).To(Equal("arn:aws-cn:s3:::finance-reports"))
+
+		denied := &s3v1alpha1.S3Bucket{ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "web"}}
+		_, err := reconciler.resolveBucketARN(context.Background(), denied, ref)
+		Expect(err).To(MatchError(ContainSubstring("does not allow access from namespace web")))
+	})
+
+	It("should report unmanaged configurations in the Drifted condition", func() {
+		s3bkt := &s3v1alpha1.S3Bucket{}
+
+		Expect(setDriftCondition(s3bkt, map[string][]string{
+			inventoryKind: {"manual-report"},
+			analyticsKind: nil,
+		})).To(BeTrue())
+		condition := meta.FindStatusCondition(s3bkt.Status.Conditions, s3v1alpha1.ConditionDrifted)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("inventory: manual-report"))
+		Expect(condition.Message).NotTo(ContainSubstring(analyticsKind))
+
+		Expect(setDriftCondition(s3bkt, map[string][]string{inventoryKind: {"manual-report"}})).To(BeFalse())
+		Expect(setDriftCondition(s3bkt, map[string][]string{})).To(BeFalse())
+		Expect(meta.IsStatusConditionFalse(s3bkt.Status.Conditions, s3v1alpha1.ConditionDrifted)).To(BeTrue())
+	})
+
+	Context("When checking created buckets for drift", func() {
+		var (
+			ctx        context.Context
+			s3bkt      *s3v1alpha1.S3Bucket
+			c          client.Client
+			reconciler *S3BucketReconciler
+			mu         sync.Mutex
+			deleted    []string
+			events     *record.FakeRecorder
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			deleted = nil
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+			s3bkt = &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "drift", Namespace: "default"},
+				Spec: s3v1alpha1.S3BucketSpec{
+					Name:      "drift-bucket",
+					Inventory: []s3v1alpha1.InventoryConfiguration{{ID: "daily"}},
+				},
+				Status: s3v1alpha1.S3BucketStatus{State: s3v1alpha1.CREATED_STATE},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+				WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+
+			// An S3 endpoint with an inventory configuration added outside the operator, lacking
+			// the analytics and intelligent-tiering APIs
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch {
+				case req.URL.Query().Has("inventory") && req.Method == http.MethodDelete:
+					mu.Lock()
+					deleted = append(deleted, req.URL.Query().Get("id"))
+					mu.Unlock()
+					w.WriteHeader(http.StatusNoContent)
+				case req.URL.Query().Has("inventory"):
+					_, _ = w.Write([]byte(`<ListInventoryConfigurationsResult>` +
+						`<InventoryConfiguration><Id>daily</Id></InventoryConfiguration>` +
+						`<InventoryConfiguration><Id>manual</Id></InventoryConfiguration>` +
+						`<IsTruncated>false</IsTruncated></ListInventoryConfigurationsResult>`))
+				default:
+					w.WriteHeader(http.StatusNotImplemented)
+					_, _ = w.Write([]byte(`<Error><Code>NotImplemented</Code><Message>Not implemented</Message></Error>`))
+				}
+			}))
+			DeferCleanup(server.Close)
+			sess, err := session.NewSession(&aws.Config{
+				Endpoint:         aws.String(server.URL),
+				Region:           aws.String("us-east-1"),
+				S3ForcePathStyle: aws.Bool(true),
+				Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			events = record.NewFakeRecorder(10)
+			reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, S3svc: s3.New(sess), DriftInterval: time.Hour,
+				Recorder: recorder.New(events)}
+		})
+
+		latest := func() *s3v1alpha1.S3Bucket {
+			bucket := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(s3bkt), bucket)).To(Succeed())
+			return bucket
+		}
+
+		It("should report configurations added outside the operator", func() {
+			requeueAfter, err := reconciler.refreshDrift(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(Equal(time.Hour))
+
+			bucket := latest()
+			Expect(bucket.Status.LastDriftCheckTime).NotTo(BeNil())
+			condition := meta.FindStatusCondition(bucket.Status.Conditions, s3v1alpha1.ConditionDrifted)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("inventory: manual"))
+			Expect(deleted).To(BeEmpty())
+			Expect(events.Events).To(Receive(And(
+				HavePrefix("Warning "+recorder.ReasonDriftDetected), ContainSubstring("inventory: manual"))))
+
+			By("waiting for the drift interval before checking again")
+			requeueAfter, err = reconciler.refreshDrift(ctx, bucket)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(And(BeNumerically(">", 59*time.Minute), BeNumerically("<=", time.Hour)))
+
+			By("warning only once while the bucket keeps drifting")
+			bucket.Status.LastDriftCheckTime = nil
+			_, err = reconciler.refreshDrift(ctx, bucket)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.Events).NotTo(Receive())
+		})
+
+		It("should remove them under the Enforce drift policy", func() {
+			s3bkt.Spec.DriftPolicy = s3v1alpha1.DriftPolicyEnforce
+			_, err := reconciler.refreshDrift(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal([]string{"manual"}))
+			Expect(meta.IsStatusConditionFalse(latest().Status.Conditions, s3v1alpha1.ConditionDrifted)).To(BeTrue())
+			Expect(events.Events).NotTo(Receive())
+		})
+
+		It("should not check buckets when disabled", func() {
+			reconciler.DriftInterval = 0
+			requeueAfter, err := reconciler.refreshDrift(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(BeZero())
+			Expect(latest().Status.LastDriftCheckTime).To(BeNil())
+		})
+	})
+})
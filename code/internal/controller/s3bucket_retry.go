@@ -0,0 +1,238 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/metrics"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/azure"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/gcs"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/recorder"
+)
+
+const (
+	// retryBaseDelay is the first backoff of a retryable failure, doubled on every attempt
+	retryBaseDelay = 5 * time.Second
+	// conflictBaseDelay is the first backoff of a conflict, doubled on every attempt
+	conflictBaseDelay = 30 * time.Second
+	// retryMaxDelay caps the backoff
+	retryMaxDelay = 15 * time.Minute
+)
+
+// terminalErrorCodes are returned by AWS, S3-compatible backends and Azure for requests that
+// fail the same way until the spec or the credentials change
+var terminalErrorCodes = map[string]bool{
+	"BucketAlreadyExists":                true,
+	"InvalidBucketName":                  true,
+	"InvalidArgument":                    true,
+	"InvalidLocationConstraint":          true,
+	"IllegalLocationConstraintException": true,
+	"InvalidRequest":                     true,
+	"MalformedXML":                       true,
+	"MalformedPolicy":                    true,
+	"InvalidTag":                         true,
+	"AccessDenied":                       true,
+	"AllAccessDisabled":                  true,
+	"InvalidAccessKeyId":                 true,
+	"SignatureDoesNotMatch":              true,
+	"InvalidClientTokenId":               true,
+	"NoCredentialProviders":              true,
+	"AuthorizationFailure":               true,
+	"AuthenticationFailed":               true,
+	"InvalidResourceName":                true,
+	"ContainerAlreadyExists":             true,
+}
+
+// conflictErrorCodes are returned for requests that collide with a concurrent operation or with
+// the contents of the bucket
+var conflictErrorCodes = map[string]bool{
+	"OperationAborted":       true,
+	"BucketNotEmpty":         true,
+	"ConcurrentModification": true,
+	"DeleteConflict":         true,
+	"ContainerBeingDeleted":  true,
+	"ContainerBeingDisabled": true,
+	"LeaseIdMissing":         true,
+	"LeaseAlreadyPresent":    true,
+	"ResourceInUse":          true,
+}
+
+// classifyError returns the retry reason of err: terminal and conflict error codes first, then
+// the HTTP status of the provider answer. Anything else, such as throttling, server errors,
+// timeouts and network failures, is retryable.
+func classifyError(err error) string {
+	code, status := errorDetails(err)
+	switch {
+	case terminalErrorCodes[code]:
+		return s3v1alpha1.RetryReasonTerminal
+	case conflictErrorCodes[code]:
+		return s3v1alpha1.RetryReasonConflict
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		return s3v1alpha1.RetryReasonRetryable
+	case status == http.StatusConflict || apierrors.IsConflict(err):
+		return s3v1alpha1.RetryReasonConflict
+	case status == http.StatusBadRequest || status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		apierrors.IsInvalid(err):
+		return s3v1alpha1.RetryReasonTerminal
+	default:
+		return s3v1alpha1.RetryReasonRetryable
+	}
+}
+
+// errorDetails returns the error code and HTTP status the provider answered err with, if any
+func errorDetails(err error) (code string, status int) {
+	var requestFailure awserr.RequestFailure
+	if errors.As(err, &requestFailure) {
+		return requestFailure.Code(), requestFailure.StatusCode()
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code(), 0
+	}
+	var azureErr *azure.APIError
+	if errors.As(err, &azureErr) {
+		return azureErr.Code, azureErr.StatusCode
+	}
+	var gcsErr *gcs.APIError
+	if errors.As(err, &gcsErr) {
+		return "", gcsErr.StatusCode
+	}
+	return "", 0
+}
+
+// retryBackoff returns how long to wait before attempt+1 after a failure of the given reason.
+// Terminal failures are not retried until the generation or the credentials change, so they get none
+func retryBackoff(reason string, attempt int32) time.Duration {
+	delay := retryBaseDelay
+	switch reason {
+	case s3v1alpha1.RetryReasonTerminal:
+		return 0
+	case s3v1alpha1.RetryReasonConflict:
+		delay = conflictBaseDelay
+	}
+	for i := int32(1); i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// pendingRetry reports whether the bucket must still wait for the backoff of its last failure,
+// and for how long. Terminal failures wait without a deadline. A spec change or new credentials
+// since the failure are retried at once.
+func pendingRetry(s3bkt *s3v1alpha1.S3Bucket, deleting bool, credentials string) (time.Duration, bool) {
+	retry := s3bkt.Status.Retry
+	if retry == nil || retry.ObservedGeneration != s3bkt.Generation || retry.CredentialsVersion != credentials ||
+		(retry.Operation == metrics.PhaseDelete) != deleting {
+		return 0, false
+	}
+	if retry.NextRetryTime == nil {
+		return 0, retry.Reason == s3v1alpha1.RetryReasonTerminal
+	}
+	wait := time.Until(retry.NextRetryTime.Time)
+	return max(wait, 0), wait > 0
+}
+
+// retryAfterFailure classifies err, records the failed attempt of operation in the status and
+// requeues the bucket once its backoff elapsed. The error is only returned when it cannot be
+// recorded, leaving the backoff to the workqueue.
+func (r *S3BucketReconciler) retryAfterFailure(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, operation string, err error) (ctrl.Result, error) {
+	retry := &s3v1alpha1.RetryStatus{
+		Operation:          operation,
+		Count:              1,
+		Reason:             classifyError(err),
+		Message:            recorder.Summarize(err),
+		ObservedGeneration: s3bkt.Generation,
+		CredentialsVersion: r.credentialsVersion(),
+	}
+	if previous := s3bkt.Status.Retry; previous != nil && previous.Operation == operation &&
+		previous.ObservedGeneration == s3bkt.Generation {
+		retry.Count = previous.Count + 1
+	}
+	delay := retryBackoff(retry.Reason, retry.Count)
+	if delay > 0 {
+		next := metav1.NewTime(time.Now().Add(delay))
+		retry.NextRetryTime = &next
+	}
+
+	if updateErr := r.updateBucketStatus(ctx, s3bkt, "", func(status *s3v1alpha1.S3BucketStatus) {
+		status.Retry = retry
+	}); updateErr != nil {
+		return ctrl.Result{}, errors.Join(err, updateErr)
+	}
+	if delay == 0 {
+		logf.FromContext(ctx).Info("Waiting for a spec or credentials change to retry failed bucket operation",
+			"BucketName", s3bkt.Spec.Name, "Operation", operation, "Attempt", retry.Count)
+		return ctrl.Result{}, nil
+	}
+	logf.FromContext(ctx).Info("Retrying failed bucket operation after backoff", "BucketName", s3bkt.Spec.Name,
+		"Operation", operation, "Reason", retry.Reason, "Attempt", retry.Count, "RetryAfter", delay)
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+// credentialsVersion returns the version of the provider clients of the bucket, or "" for the
+// operator credentials
+func (r *S3BucketReconciler) credentialsVersion() string {
+	if r.clients == nil {
+		return ""
+	}
+	return r.clients.Version
+}
+
+// bucketsForProviderConfig maps an S3ProviderConfig to the buckets using it that wait for new
+// credentials after a terminal failure, including those relying on the default config
+func (r *S3BucketReconciler) bucketsForProviderConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	buckets := &s3v1alpha1.S3BucketList{}
+	if err := r.List(ctx, buckets); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list S3Bucket resources for provider config", "ProviderConfig", obj.GetName())
+		return nil
+	}
+
+	isDefault := obj.GetAnnotations()[s3v1alpha1.DefaultProviderConfigAnnotation] == "true"
+	requests := []reconcile.Request{}
+	for _, item := range buckets.Items {
+		if item.Status.Retry == nil || item.Status.Retry.Reason != s3v1alpha1.RetryReasonTerminal {
+			continue
+		}
+		if item.Spec.ProviderConfigRef == obj.GetName() || (item.Spec.ProviderConfigRef == "" && isDefault) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+		}
+	}
+	return requests
+}
+
+// resetRetry clears the retry status once the failed operation succeeded
+func (r *S3BucketReconciler) resetRetry(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	if s3bkt.Status.Retry == nil {
+		return nil
+	}
+	return r.updateBucketStatus(ctx, s3bkt, "", func(status *s3v1alpha1.S3BucketStatus) {
+		status.Retry = nil
+	})
+}
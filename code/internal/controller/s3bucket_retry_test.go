@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/azure"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/gcs"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+)
+
+var _ = Describe("S3Bucket retries", func() {
+	awsError := func(code string, status int) error {
+		return fmt.Errorf("S3 CreateBucket API call failed: %w",
+			awserr.NewRequestFailure(awserr.New(code, "message", nil), status, "REQ123"))
+	}
+
+	DescribeTable("should classify provider errors",
+		func(err error, reason string) {
+			Expect(classifyError(err)).To(Equal(reason))
+		},
+		Entry("throttling", awsError("SlowDown", 503), s3v1alpha1.RetryReasonRetryable),
+		Entry("server errors", awsError("InternalError", 500), s3v1alpha1.RetryReasonRetryable),
+		Entry("timeouts", fmt.Errorf("bucket did not become ready: %w", context.DeadlineExceeded), s3v1alpha1.RetryReasonRetryable),
+		Entry("network failures", awserr.New("RequestError", "send request failed", errors.New("connection refused")), s3v1alpha1.RetryReasonRetryable),
+		Entry("names taken by another account", awsError("BucketAlreadyExists", 409), s3v1alpha1.RetryReasonTerminal),
+		Entry("invalid configurations", awsError("InvalidBucketName", 400), s3v1alpha1.RetryReasonTerminal),
+		Entry("denied access", awsError("AccessDenied", 403), s3v1alpha1.RetryReasonTerminal),
+		Entry("missing credentials", awserr.New("NoCredentialProviders", "no valid providers in chain", nil), s3v1alpha1.RetryReasonTerminal),
+		Entry("concurrent operations", awsError("OperationAborted", 409), s3v1alpha1.RetryReasonConflict),
+		Entry("buckets not empty", awsError("BucketNotEmpty", 409), s3v1alpha1.RetryReasonConflict),
+		Entry("Azure containers being deleted", &azure.APIError{StatusCode: 409, Code: "ContainerBeingDeleted"}, s3v1alpha1.RetryReasonConflict),
+		Entry("Azure denied access", &azure.APIError{StatusCode: 403, Code: "AuthorizationFailure"}, s3v1alpha1.RetryReasonTerminal),
+		Entry("GCS rate limits", fmt.Errorf("GCS buckets.insert API call failed: %w", &gcs.APIError{StatusCode: 429}), s3v1alpha1.RetryReasonRetryable),
+		Entry("GCS conflicts", &gcs.APIError{StatusCode: 409}, s3v1alpha1.RetryReasonConflict),
+		Entry("GCS invalid requests", &gcs.APIError{StatusCode: 400}, s3v1alpha1.RetryReasonTerminal),
+		Entry("Kubernetes conflicts", apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "data", errors.New("modified")), s3v1alpha1.RetryReasonConflict),
+		Entry("unknown errors", errors.New("boom"), s3v1alpha1.RetryReasonRetryable),
+	)
+
+	It("should back off exponentially up to the cap", func() {
+		Expect(retryBackoff(s3v1alpha1.RetryReasonRetryable, 1)).To(Equal(5 * time.Second))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonRetryable, 2)).To(Equal(10 * time.Second))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonRetryable, 4)).To(Equal(40 * time.Second))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonRetryable, 100)).To(Equal(retryMaxDelay))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonConflict, 1)).To(Equal(30 * time.Second))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonConflict, 3)).To(Equal(2 * time.Minute))
+		Expect(retryBackoff(s3v1alpha1.RetryReasonTerminal, 1)).To(BeZero())
+	})
+
+	Context("When an operation failed for good", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			reconciler *S3BucketReconciler
+			s3bkt      *s3v1alpha1.S3Bucket
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+			s3bkt = &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "denied", Namespace: "default", Generation: 1},
+				Spec:       s3v1alpha1.S3BucketSpec{Name: "denied-bucket", ProviderConfigRef: "aws"},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+				WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+			reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, clients: &s3client.Clients{Version: "v1"}}
+		})
+
+		It("should wait for a new generation or new credentials instead of requeueing", func() {
+			result, err := reconciler.retryAfterFailure(ctx, s3bkt, "create", awsError("AccessDenied", 403))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			latest := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(s3bkt), latest)).To(Succeed())
+			Expect(latest.Status.Retry.Reason).To(Equal(s3v1alpha1.RetryReasonTerminal))
+			Expect(latest.Status.Retry.NextRetryTime).To(BeNil())
+			Expect(latest.Status.Retry.CredentialsVersion).To(Equal("v1"))
+
+			wait, pending := pendingRetry(latest, false, "v1")
+			Expect(pending).To(BeTrue())
+			Expect(wait).To(BeZero())
+
+			By("retrying once the credentials change")
+			_, pending = pendingRetry(latest, false, "v2")
+			Expect(pending).To(BeFalse())
+
+			By("retrying once the spec changes")
+			latest.Generation = 2
+			_, pending = pendingRetry(latest, false, "v1")
+			Expect(pending).To(BeFalse())
+		})
+
+		It("should enqueue the buckets of a provider config whose credentials changed", func() {
+			_, err := reconciler.retryAfterFailure(ctx, s3bkt, "create", awsError("AccessDenied", 403))
+			Expect(err).NotTo(HaveOccurred())
+
+			config := &s3v1alpha1.S3ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "aws"}}
+			Expect(reconciler.bucketsForProviderConfig(ctx, config)).To(ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKeyFromObject(s3bkt)}))
+			other := &s3v1alpha1.S3ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "gcs"}}
+			Expect(reconciler.bucketsForProviderConfig(ctx, other)).To(BeEmpty())
+		})
+	})
+})
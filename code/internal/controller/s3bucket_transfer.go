@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// accelerateEndpoint returns the Transfer Acceleration endpoint URL of a bucket
+func accelerateEndpoint(bucket string) string {
+	return fmt.Sprintf("https://%s.s3-accelerate.amazonaws.com", bucket)
+}
+
+// applyAccelerateConfiguration enables or suspends Transfer Acceleration and returns the observed state
+func (r *S3BucketReconciler) applyAccelerateConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (string, error) {
+	log := logf.FromContext(ctx)
+
+	current, err := r.S3svc.GetBucketAccelerateConfigurationWithContext(ctx, &s3.GetBucketAccelerateConfigurationInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 GetBucketAccelerateConfiguration API call failed: %w", err)
+	}
+
+	desired := s3.BucketAccelerateStatusSuspended
+	if s3bkt.Spec.Acceleration {
+		desired = s3.BucketAccelerateStatusEnabled
+	}
+
+	// A bucket that never had acceleration configured reports no status, which is equivalent to Suspended
+	observed := aws.StringValue(current.Status)
+	if observed == desired || (observed == "" && desired == s3.BucketAccelerateStatusSuspended) {
+		return observed, nil
+	}
+
+	log.Info("Updating transfer acceleration", "BucketName", s3bkt.Spec.Name, "Status", desired)
+	_, err = r.S3svc.PutBucketAccelerateConfigurationWithContext(ctx, &s3.PutBucketAccelerateConfigurationInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+		AccelerateConfiguration: &s3.AccelerateConfiguration{
+			Status: aws.String(desired),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 PutBucketAccelerateConfiguration API call failed: %w", err)
+	}
+
+	return desired, nil
+}
+
+// applyRequestPaymentConfiguration sets who pays for requests and returns the observed payer
+func (r *S3BucketReconciler) applyRequestPaymentConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (string, error) {
+	log := logf.FromContext(ctx)
+
+	current, err := r.S3svc.GetBucketRequestPaymentWithContext(ctx, &s3.GetBucketRequestPaymentInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 GetBucketRequestPayment API call failed: %w", err)
+	}
+
+	desired := s3.PayerBucketOwner
+	if s3bkt.Spec.RequesterPays {
+		desired = s3.PayerRequester
+	}
+
+	if aws.StringValue(current.Payer) == desired {
+		return desired, nil
+	}
+
+	log.Info("Updating request payment configuration", "BucketName", s3bkt.Spec.Name, "Payer", desired)
+	_, err = r.S3svc.PutBucketRequestPaymentWithContext(ctx, &s3.PutBucketRequestPaymentInput{
+		Bucket: aws.String(s3bkt.Spec.Name),
+		RequestPaymentConfiguration: &s3.RequestPaymentConfiguration{
+			Payer: aws.String(desired),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 PutBucketRequestPayment API call failed: %w", err)
+	}
+
+	return desired, nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3Bucket transfer configuration", func() {
+	It("should publish the accelerate endpoint only when acceleration is enabled", func() {
+		s3bkt := &s3v1alpha1.S3Bucket{
+			Spec: s3v1alpha1.S3BucketSpec{Name: "datasets", DefaultStorageClass: "STANDARD_IA"},
+			Status: s3v1alpha1.S3BucketStatus{
+				AccelerationStatus: "Enabled",
+				RequestPayer:       "Requester",
+			},
+		}
+
+		data := configurationData(s3bkt)
+		Expect(data).To(HaveKeyWithValue("AccelerateEndpoint", "https://datasets.s3-accelerate.amazonaws.com"))
+		Expect(data).To(HaveKeyWithValue("RequesterPays", "true"))
+		Expect(data).To(HaveKeyWithValue("DefaultStorageClass", "STANDARD_IA"))
+
+		s3bkt.Status.AccelerationStatus = "Suspended"
+		Expect(configurationData(s3bkt)).To(HaveKeyWithValue("AccelerateEndpoint", ""))
+	})
+})
@@ -0,0 +1,195 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/s3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+const (
+	// listPageSize is the number of keys requested per ListObjectsV2 page
+	listPageSize = 1000
+	// storageMetricsLookback covers the daily storage metrics, which S3 publishes with a delay
+	storageMetricsLookback = 72 * time.Hour
+	// storageMetricsPeriod is the period of the daily storage metrics
+	storageMetricsPeriod = 24 * 60 * 60
+)
+
+// refreshBucketUsage collects the usage of the bucket when the recorded one is older than the
+// usage interval, and returns when to collect it next. A zero interval disables collection
+func (r *S3BucketReconciler) refreshBucketUsage(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (time.Duration, error) {
+	if r.UsageInterval <= 0 {
+		return 0, nil
+	}
+	if usage := s3bkt.Status.Usage; usage != nil {
+		if age := time.Since(usage.LastUpdated.Time); age < r.UsageInterval {
+			return r.UsageInterval - age, nil
+		}
+	}
+
+	usage, err := r.collectBucketUsage(ctx, s3bkt)
+	if err != nil {
+		return r.UsageInterval, err
+	}
+	if usage == nil {
+		// The provider cannot measure its buckets
+		return 0, nil
+	}
+	usage.LastUpdated = metav1.Now()
+	if err := r.updateBucketStatus(ctx, s3bkt, s3bkt.Status.State, func(status *s3v1alpha1.S3BucketStatus) {
+		status.Usage = usage
+	}); err != nil {
+		return 0, fmt.Errorf("failed to record bucket usage: %w", err)
+	}
+	s3bkt.Status.Usage = usage
+	return r.UsageInterval, nil
+}
+
+// collectBucketUsage measures the bucket: on AWS through the CloudWatch storage metrics, on
+// S3-compatible storage by listing it, and otherwise through the provider when it supports it.
+// It returns nil when the usage cannot be measured
+func (r *S3BucketReconciler) collectBucketUsage(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*s3v1alpha1.BucketUsage, error) {
+	log := logf.FromContext(ctx)
+
+	if r.manager != nil {
+		reporter, ok := r.manager.(provider.UsageReporter)
+		if !ok {
+			return nil, nil
+		}
+		return reporter.Usage(ctx, s3bkt, r.UsageMaxObjects)
+	}
+
+	if r.clients != nil {
+		if cloudWatch := r.clients.CloudWatch(r.bucketRegion(s3bkt)); cloudWatch != nil {
+			usage, err := storageMetricsUsage(ctx, cloudWatch, s3bkt.Spec.Name)
+			switch {
+			case err != nil:
+				log.Info("Failed to read bucket storage metrics, listing the bucket instead", "BucketName", s3bkt.Spec.Name, "Error", err.Error())
+			case usage != nil:
+				return usage, nil
+			}
+			// S3 publishes the storage metrics of a new bucket after about a day
+		}
+	}
+	return r.listBucketUsage(ctx, s3bkt)
+}
+
+// listBucketUsage counts the objects of the bucket and sums their size, stopping after
+// UsageMaxObjects objects when it is positive
+func (r *S3BucketReconciler) listBucketUsage(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*s3v1alpha1.BucketUsage, error) {
+	usage := &s3v1alpha1.BucketUsage{Source: s3v1alpha1.UsageSourceListing}
+	err := r.S3svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s3bkt.Spec.Name),
+		MaxKeys: aws.Int64(listPageSize),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			if r.UsageMaxObjects > 0 && usage.ObjectCount >= r.UsageMaxObjects {
+				usage.Truncated = true
+				return false
+			}
+			usage.ObjectCount++
+			usage.SizeBytes += aws.Int64Value(object.Size)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects of bucket %s: %w", s3bkt.Spec.Name, err)
+	}
+	return usage, nil
+}
+
+// storageMetricsUsage reads the daily storage metrics of the bucket. The size sums every storage
+// type, e.g. StandardStorage and GlacierStorage. It returns nil when no metrics were published yet
+func storageMetricsUsage(ctx context.Context, cloudWatch *cloudwatch.CloudWatch, bucket string) (*s3v1alpha1.BucketUsage, error) {
+	var storageTypes []*cloudwatch.Metric
+	err := cloudWatch.ListMetricsPagesWithContext(ctx, &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String("AWS/S3"),
+		MetricName: aws.String("BucketSizeBytes"),
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String("BucketName"), Value: aws.String(bucket)}},
+	}, func(page *cloudwatch.ListMetricsOutput, _ bool) bool {
+		storageTypes = append(storageTypes, page.Metrics...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CloudWatch ListMetrics API call failed: %w", err)
+	}
+
+	usage := &s3v1alpha1.BucketUsage{Source: s3v1alpha1.UsageSourceCloudWatch}
+	found := false
+	for _, metric := range storageTypes {
+		size, ok, err := latestStorageMetric(ctx, cloudWatch, "BucketSizeBytes", metric.Dimensions)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			usage.SizeBytes += int64(size)
+			found = true
+		}
+	}
+	count, ok, err := latestStorageMetric(ctx, cloudWatch, "NumberOfObjects", []*cloudwatch.Dimension{
+		{Name: aws.String("BucketName"), Value: aws.String(bucket)},
+		{Name: aws.String("StorageType"), Value: aws.String("AllStorageTypes")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found || !ok {
+		return nil, nil
+	}
+	usage.ObjectCount = int64(count)
+	return usage, nil
+}
+
+// latestStorageMetric returns the most recent daily value of a storage metric, and false when
+// there is none
+func latestStorageMetric(ctx context.Context, cloudWatch *cloudwatch.CloudWatch, name string, dimensions []*cloudwatch.Dimension) (float64, bool, error) {
+	now := time.Now()
+	output, err := cloudWatch.GetMetricStatisticsWithContext(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/S3"),
+		MetricName: aws.String(name),
+		Dimensions: dimensions,
+		StartTime:  aws.Time(now.Add(-storageMetricsLookback)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int64(storageMetricsPeriod),
+		Statistics: []*string{aws.String(cloudwatch.StatisticAverage)},
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("CloudWatch GetMetricStatistics API call failed for %s: %w", name, err)
+	}
+
+	var latest *cloudwatch.Datapoint
+	for _, datapoint := range output.Datapoints {
+		if latest == nil || aws.TimeValue(datapoint.Timestamp).After(aws.TimeValue(latest.Timestamp)) {
+			latest = datapoint
+		}
+	}
+	if latest == nil {
+		return 0, false, nil
+	}
+	return aws.Float64Value(latest.Average), true, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/local"
+)
+
+var _ = Describe("S3Bucket usage", func() {
+	var (
+		ctx        context.Context
+		root       string
+		s3bkt      *s3v1alpha1.S3Bucket
+		c          client.Client
+		reconciler *S3BucketReconciler
+	)
+
+	write := func(key, body string) {
+		path := filepath.Join(root, "usage-bucket", key)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(body), 0o644)).To(Succeed())
+	}
+
+	recordedUsage := func() *s3v1alpha1.BucketUsage {
+		latest := &s3v1alpha1.S3Bucket{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(s3bkt), latest)).To(Succeed())
+		return latest.Status.Usage
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "usage", Namespace: "default"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: "usage-bucket"},
+			Status:     s3v1alpha1.S3BucketStatus{State: s3v1alpha1.CREATED_STATE},
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(s3bkt).
+			WithStatusSubresource(&s3v1alpha1.S3Bucket{}).Build()
+
+		// An S3-compatible endpoint serving the buckets of a directory
+		root = GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(root, "usage-bucket"), 0o755)).To(Succeed())
+		server := httptest.NewServer(&local.Server{Root: root})
+		DeferCleanup(server.Close)
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String("us-east-1"),
+			S3ForcePathStyle: aws.Bool(true),
+			Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &S3BucketReconciler{Client: c, Scheme: scheme, S3svc: s3.New(sess), UsageInterval: time.Hour}
+	})
+
+	It("should list S3-compatible buckets and record their usage", func() {
+		write("a", "12345")
+		write("logs/b", "123")
+
+		requeueAfter, err := reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(time.Hour))
+
+		usage := recordedUsage()
+		Expect(usage).NotTo(BeNil())
+		Expect(usage.SizeBytes).To(BeEquivalentTo(8))
+		Expect(usage.ObjectCount).To(BeEquivalentTo(2))
+		Expect(usage.Source).To(Equal(s3v1alpha1.UsageSourceListing))
+		Expect(usage.Truncated).To(BeFalse())
+		Expect(usage.LastUpdated.IsZero()).To(BeFalse())
+
+		By("keeping the recorded usage until the interval elapsed")
+		write("c", "1")
+		requeueAfter, err = reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(BeNumerically("<=", time.Hour))
+		Expect(recordedUsage().ObjectCount).To(BeEquivalentTo(2))
+
+		By("collecting it again once the interval elapsed")
+		s3bkt.Status.Usage.LastUpdated = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		_, err = reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recordedUsage().ObjectCount).To(BeEquivalentTo(3))
+	})
+
+	It("should stop listing at the bound", func() {
+		write("a", "1")
+		write("b", "1")
+		write("c", "1")
+		reconciler.UsageMaxObjects = 2
+
+		_, err := reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recordedUsage().ObjectCount).To(BeEquivalentTo(2))
+		Expect(recordedUsage().Truncated).To(BeTrue())
+	})
+
+	It("should ask providers that measure their buckets", func() {
+		write("a", "1234")
+		reconciler.S3svc = nil
+		reconciler.manager = local.New(root, "")
+
+		_, err := reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recordedUsage().Source).To(Equal(s3v1alpha1.UsageSourceProvider))
+		Expect(recordedUsage().SizeBytes).To(BeEquivalentTo(4))
+	})
+
+	It("should not collect the usage when disabled", func() {
+		reconciler.UsageInterval = 0
+
+		requeueAfter, err := reconciler.refreshBucketUsage(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(BeZero())
+		Expect(recordedUsage()).To(BeNil())
+	})
+})
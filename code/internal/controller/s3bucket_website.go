@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	websiteServiceName = "%s-s3-website"
+	// errCodeNoSuchWebsiteConfiguration is returned by DeleteBucketWebsite when nothing is configured
+	errCodeNoSuchWebsiteConfiguration = "NoSuchWebsiteConfiguration"
+)
+
+// websiteDashRegions are the regions whose website endpoint uses the legacy
+// "s3-website-<region>" form instead of "s3-website.<region>".
+var websiteDashRegions = map[string]bool{
+	"us-east-1":      true,
+	"us-west-1":      true,
+	"us-west-2":      true,
+	"ap-southeast-1": true,
+	"ap-southeast-2": true,
+	"ap-northeast-1": true,
+	"eu-west-1":      true,
+	"sa-east-1":      true,
+	"us-gov-west-1":  true,
+}
+
+// websiteEndpoint returns the static website endpoint URL of a bucket in the given region
+func websiteEndpoint(bucket, region string) string {
+	separator := "."
+	if websiteDashRegions[region] {
+		separator = "-"
+	}
+	return fmt.Sprintf("http://%s.s3-website%s%s.amazonaws.com", bucket, separator, region)
+}
+
+// buildWebsiteConfiguration converts the website spec into the S3 API representation
+func buildWebsiteConfiguration(website *s3v1alpha1.WebsiteSpec) *s3.WebsiteConfiguration {
+	config := &s3.WebsiteConfiguration{
+		IndexDocument: &s3.IndexDocument{
+			Suffix: aws.String(website.IndexDocument),
+		},
+	}
+	if website.IndexDocument == "" {
+		config.IndexDocument.Suffix = aws.String("index.html")
+	}
+	if website.ErrorDocument != "" {
+		config.ErrorDocument = &s3.ErrorDocument{
+			Key: aws.String(website.ErrorDocument),
+		}
+	}
+
+	for _, rule := range website.RoutingRules {
+		routingRule := &s3.RoutingRule{
+			Redirect: &s3.Redirect{
+				HostName:             optionalString(rule.Redirect.HostName),
+				HttpRedirectCode:     optionalString(rule.Redirect.HTTPRedirectCode),
+				Protocol:             optionalString(rule.Redirect.Protocol),
+				ReplaceKeyPrefixWith: optionalString(rule.Redirect.ReplaceKeyPrefixWith),
+				ReplaceKeyWith:       optionalString(rule.Redirect.ReplaceKeyWith),
+			},
+		}
+		if rule.Condition != nil {
+			routingRule.Condition = &s3.Condition{
+				KeyPrefixEquals:             optionalString(rule.Condition.KeyPrefixEquals),
+				HttpErrorCodeReturnedEquals: optionalString(rule.Condition.HTTPErrorCodeReturnedEquals),
+			}
+		}
+		config.RoutingRules = append(config.RoutingRules, routingRule)
+	}
+
+	return config
+}
+
+// optionalString returns nil for empty strings so they are omitted from S3 API requests
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// applyWebsiteConfiguration configures static website hosting on the bucket and returns
+// the website endpoint, or removes the configuration when spec.website is no longer set
+func (r *S3BucketReconciler) applyWebsiteConfiguration(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (string, error) {
+	log := logf.FromContext(ctx)
+
+	if s3bkt.Spec.Website == nil {
+		if s3bkt.Status.WebsiteEndpoint == "" {
+			return "", nil
+		}
+
+		log.Info("Removing website configuration from bucket", "BucketName", s3bkt.Spec.Name)
+		_, err := r.S3svc.DeleteBucketWebsiteWithContext(ctx, &s3.DeleteBucketWebsiteInput{
+			Bucket: aws.String(s3bkt.Spec.Name),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == errCodeNoSuchWebsiteConfiguration {
+				return "", nil
+			}
+			return "", fmt.Errorf("S3 DeleteBucketWebsite API call failed: %w", err)
+		}
+		return "", nil
+	}
+
+	log.Info("Applying website configuration to bucket", "BucketName", s3bkt.Spec.Name)
+	_, err := r.S3svc.PutBucketWebsiteWithContext(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               aws.String(s3bkt.Spec.Name),
+		WebsiteConfiguration: buildWebsiteConfiguration(s3bkt.Spec.Website),
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 PutBucketWebsite API call failed: %w", err)
+	}
+
+	return websiteEndpoint(s3bkt.Spec.Name, r.bucketRegion(s3bkt)), nil
+}
+
+// ensureWebsiteService creates or updates the ExternalName Service pointing at the website
+// endpoint, or deletes it when it is no longer requested
+func (r *S3BucketReconciler) ensureWebsiteService(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, endpoint string) error {
+	log := logf.FromContext(ctx)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(websiteServiceName, s3bkt.Name),
+			Namespace: s3bkt.Namespace,
+		},
+	}
+
+	if s3bkt.Spec.Website == nil || !s3bkt.Spec.Website.ExternalService || endpoint == "" {
+		if err := r.Delete(ctx, svc); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete website Service: %w", err)
+		}
+		return nil
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid website endpoint %q: %w", endpoint, err)
+	}
+
+	log.Info("Ensuring website Service for bucket", "BucketName", s3bkt.Spec.Name, "ServiceName", svc.Name)
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		svc.Spec.Type = corev1.ServiceTypeExternalName
+		svc.Spec.ExternalName = endpointURL.Hostname()
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name: "http",
+			Port: 80,
+		}}
+		return controllerutil.SetControllerReference(s3bkt, svc, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update website Service: %w", err)
+	}
+
+	return nil
+}
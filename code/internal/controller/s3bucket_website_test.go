@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3Bucket website hosting", func() {
+	It("should build the website endpoint for legacy and current regions", func() {
+		Expect(websiteEndpoint("site", "us-west-2")).To(Equal("http://site.s3-website-us-west-2.amazonaws.com"))
+		Expect(websiteEndpoint("site", "eu-central-1")).To(Equal("http://site.s3-website.eu-central-1.amazonaws.com"))
+	})
+
+	It("should convert the website spec into an S3 website configuration", func() {
+		config := buildWebsiteConfiguration(&s3v1alpha1.WebsiteSpec{
+			ErrorDocument: "404.html",
+			RoutingRules: []s3v1alpha1.RoutingRule{{
+				Condition: &s3v1alpha1.RoutingRuleCondition{KeyPrefixEquals: "docs/"},
+				Redirect:  s3v1alpha1.RoutingRuleRedirect{ReplaceKeyPrefixWith: "documents/"},
+			}},
+		})
+
+		Expect(aws.StringValue(config.IndexDocument.Suffix)).To(Equal("index.html"))
+		Expect(aws.StringValue(config.ErrorDocument.Key)).To(Equal("404.html"))
+		Expect(config.RoutingRules).To(HaveLen(1))
+		Expect(aws.StringValue(config.RoutingRules[0].Condition.KeyPrefixEquals)).To(Equal("docs/"))
+		Expect(config.RoutingRules[0].Condition.HttpErrorCodeReturnedEquals).To(BeNil())
+		Expect(aws.StringValue(config.RoutingRules[0].Redirect.ReplaceKeyPrefixWith)).To(Equal("documents/"))
+		Expect(config.RoutingRules[0].Redirect.HostName).To(BeNil())
+	})
+})
@@ -0,0 +1,397 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/access"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	accessSecretName           = "%s-s3-access"
+	s3BucketAccessFinalizer    = "s3bucketaccess.s3.acme.io/finalizer" // Finalizer string to be added to S3BucketAccess resources
+	bucketAccessIdentityPrefix = "s3access"
+	// bucketRefIndex indexes S3BucketAccess resources by the namespace/name of the referenced S3Bucket
+	bucketRefIndex = "spec.bucketRef"
+)
+
+// S3BucketAccessReconciler reconciles a S3BucketAccess object
+type S3BucketAccessReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	IAMsvc *iam.IAM // AWS IAM service client used when Clients is not set
+	// Clients hands out the AWS clients of each S3ProviderConfig, defined in main.go
+	Clients *s3client.Cache
+}
+
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketaccesses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketaccesses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketaccesses/finalizers,verbs=update
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3buckets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile grants the access described by an S3BucketAccess through a dedicated IAM user
+// scoped to the referenced bucket and prefix, and writes its credentials to a Secret.
+func (r *S3BucketAccessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	log.Info("Reconciling S3BucketAccess", "NamespacedName", req.NamespacedName)
+
+	bktAccess := &s3v1alpha1.S3BucketAccess{}
+	if err := r.Get(ctx, req.NamespacedName, bktAccess); err != nil {
+		log.Info("S3BucketAccess resource not found, ignoring since object must be deleted")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Revoke the access when the resource is being deleted
+	if !bktAccess.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(bktAccess, s3BucketAccessFinalizer) {
+			log.Info("Revoking bucket access", "BucketAccess", req.NamespacedName)
+			if err := r.revokeAccess(ctx, bktAccess); err != nil {
+				log.Error(err, "Failed to revoke bucket access")
+				return ctrl.Result{}, err
+			}
+			if err := r.removeFinalizer(ctx, bktAccess); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(bktAccess, s3BucketAccessFinalizer) {
+		log.Info("Adding finalizer to S3BucketAccess", "BucketAccess", req.NamespacedName)
+		controllerutil.AddFinalizer(bktAccess, s3BucketAccessFinalizer)
+		if err := r.Update(ctx, bktAccess); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Resolve the referenced bucket
+	bucket, reason, err := r.resolveBucket(ctx, bktAccess)
+	if err != nil {
+		log.Info("Bucket access cannot be granted yet", "Reason", reason, "Message", err.Error())
+		var revoked []func(*s3v1alpha1.S3BucketAccessStatus)
+		// A deleted bucket, or one that no longer allows the namespace, loses the grant
+		if (reason == "BucketNotFound" || reason == "NamespaceNotAllowed") && bktAccess.Status.Access != nil {
+			log.Info("Revoking bucket access", "BucketAccess", req.NamespacedName, "Reason", reason)
+			if revokeErr := r.revokeAccess(ctx, bktAccess); revokeErr != nil {
+				log.Error(revokeErr, "Failed to revoke bucket access")
+				return ctrl.Result{}, revokeErr
+			}
+			revoked = append(revoked, func(status *s3v1alpha1.S3BucketAccessStatus) {
+				status.BucketName = ""
+				status.Access = nil
+				status.ProviderConfigRef = ""
+				status.BucketNamespace = ""
+			})
+		}
+		if statusErr := r.updateAccessStatus(ctx, bktAccess, metav1.ConditionFalse, reason, err.Error(), revoked...); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		// The S3Bucket watch requeues the access once the bucket changes
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	policy, err := access.BucketPolicy(bucket.Spec.Name, defaultString(bktAccess.Spec.Level, access.LevelRead), bktAccess.Spec.Prefix)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	iamSvc, err := r.iamClient(ctx, bucket.Spec.ProviderConfigRef, bucket.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	accessStatus, err := ensureAccessIdentity(ctx, r.Client, r.Scheme, access.NewProvisioner(iamSvc), bktAccess, accessRequest{
+		identityType: s3v1alpha1.IdentityTypeUser,
+		identityName: access.IdentityName(bucketAccessIdentityPrefix, bktAccess.Namespace, bktAccess.Name),
+		policy:       policy,
+		secretName:   bucketAccessSecretName(bktAccess),
+		data: map[string]string{
+			bucketNameKey:   bucket.Spec.Name,
+			regionKey:       bucket.Spec.Region,
+			bucketPrefixKey: bktAccess.Spec.Prefix,
+		},
+	})
+	if err != nil {
+		log.Error(err, "Failed to grant bucket access")
+		// The identity may exist already, record the account it lives in
+		if statusErr := r.updateAccessStatus(ctx, bktAccess, metav1.ConditionFalse, "ProvisioningFailed", err.Error(),
+			grantedThrough(bucket)); statusErr != nil {
+			log.Error(statusErr, "Failed to update S3BucketAccess status")
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateAccessStatus(ctx, bktAccess, metav1.ConditionTrue, "Granted",
+		fmt.Sprintf("Credentials for bucket %s are in Secret %s", bucket.Spec.Name, accessStatus.SecretName),
+		grantedThrough(bucket), func(status *s3v1alpha1.S3BucketAccessStatus) {
+			status.BucketName = bucket.Spec.Name
+			status.Access = accessStatus
+		}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Bucket access granted", "BucketAccess", req.NamespacedName, "BucketName", bucket.Spec.Name)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *S3BucketAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &s3v1alpha1.S3BucketAccess{}, bucketRefIndex,
+		func(obj client.Object) []string {
+			bktAccess := obj.(*s3v1alpha1.S3BucketAccess)
+			return []string{bucketRefKey(bktAccess)}
+		}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&s3v1alpha1.S3BucketAccess{}).
+		Watches(&s3v1alpha1.S3Bucket{}, handler.EnqueueRequestsFromMapFunc(r.accessesForBucket)).
+		Named("s3bucketaccess").
+		Complete(r)
+}
+
+// accessesForBucket maps an S3Bucket to the S3BucketAccess resources referencing it
+func (r *S3BucketAccessReconciler) accessesForBucket(ctx context.Context, obj client.Object) []reconcile.Request {
+	accesses := &s3v1alpha1.S3BucketAccessList{}
+	if err := r.List(ctx, accesses, client.MatchingFields{bucketRefIndex: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list S3BucketAccess resources for bucket", "Bucket", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(accesses.Items))
+	for _, item := range accesses.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
+
+// resolveBucket returns the referenced S3Bucket once it is usable, or the reason it is not
+func (r *S3BucketAccessReconciler) resolveBucket(ctx context.Context, bktAccess *s3v1alpha1.S3BucketAccess) (*s3v1alpha1.S3Bucket, string, error) {
+	ref := bktAccess.Spec.BucketRef
+	namespace := defaultString(ref.Namespace, bktAccess.Namespace)
+
+	bucket := &s3v1alpha1.S3Bucket{}
+	if err := r.Get(ctx, bucketRefName(bktAccess), bucket); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, "BucketNotFound", fmt.Errorf("S3Bucket %s/%s not found", namespace, ref.Name)
+		}
+		return nil, "BucketLookupFailed", fmt.Errorf("failed to get S3Bucket %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	if !namespaceAllowed(bucket, bktAccess.Namespace) {
+		return nil, "NamespaceNotAllowed", fmt.Errorf("S3Bucket %s/%s does not allow access from namespace %s", namespace, ref.Name, bktAccess.Namespace)
+	}
+
+	if bucket.Status.State != s3v1alpha1.CREATED_STATE {
+		return nil, "BucketNotReady", fmt.Errorf("S3Bucket %s/%s is in state %q", namespace, ref.Name, bucket.Status.State)
+	}
+
+	return bucket, "", nil
+}
+
+// namespaceAllowed reports whether S3BucketAccess resources in namespace may reference the bucket
+func namespaceAllowed(bucket *s3v1alpha1.S3Bucket, namespace string) bool {
+	if bucket.Namespace == namespace {
+		return true
+	}
+	for _, allowed := range bucket.Spec.AllowedAccessNamespaces {
+		if allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// grantedThrough records the provider config and namespace of the bucket access is granted to
+func grantedThrough(bucket *s3v1alpha1.S3Bucket) func(*s3v1alpha1.S3BucketAccessStatus) {
+	return func(status *s3v1alpha1.S3BucketAccessStatus) {
+		status.ProviderConfigRef = bucket.Spec.ProviderConfigRef
+		status.BucketNamespace = bucket.Namespace
+	}
+}
+
+// revokeAccess deletes the IAM user of an S3BucketAccess and its credentials Secret. The user
+// lives in the account recorded when access was granted or, for older grants, in the account
+// of the bucket while it still exists
+func (r *S3BucketAccessReconciler) revokeAccess(ctx context.Context, bktAccess *s3v1alpha1.S3BucketAccess) error {
+	identityName := access.IdentityName(bucketAccessIdentityPrefix, bktAccess.Namespace, bktAccess.Name)
+	secretName := types.NamespacedName{Name: bucketAccessSecretName(bktAccess), Namespace: bktAccess.Namespace}
+
+	providerConfig, namespace := bktAccess.Status.ProviderConfigRef, bktAccess.Status.BucketNamespace
+	known := namespace != ""
+	if !known {
+		bucket := &s3v1alpha1.S3Bucket{}
+		err := r.Get(ctx, bucketRefName(bktAccess), bucket)
+		switch {
+		case err == nil:
+			providerConfig, namespace, known = bucket.Spec.ProviderConfigRef, bucket.Namespace, true
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get S3Bucket %s: %w", bucketRefName(bktAccess), err)
+		case bktAccess.Status.Access == nil:
+			// Nothing was granted, only a credentials Secret may be left
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName.Name, Namespace: secretName.Namespace}}
+			if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete credentials Secret: %w", err)
+			}
+			return nil
+		}
+	}
+
+	iamSvc, err := r.iamClient(ctx, providerConfig, namespace)
+	if err != nil {
+		return err
+	}
+	if err := r.checkGrantingAccount(ctx, bktAccess, providerConfig, namespace); err != nil {
+		return err
+	}
+	provisioner := access.NewProvisioner(iamSvc)
+	if !known {
+		// A user missing from the default account may live in the account that granted access
+		exists, err := provisioner.UserExists(ctx, identityName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("IAM user %s not found and the account that granted access to the deleted S3Bucket %s is unknown, "+
+				"delete the user and remove the finalizer %s manually", identityName, bucketRefName(bktAccess), s3BucketAccessFinalizer)
+		}
+	}
+	return deleteAccessIdentity(ctx, r.Client, provisioner, s3v1alpha1.IdentityTypeUser, identityName, secretName)
+}
+
+// checkGrantingAccount makes sure the clients of the provider config act in the account of the
+// IAM user recorded at grant time, which differs once the default provider config moved
+func (r *S3BucketAccessReconciler) checkGrantingAccount(ctx context.Context, bktAccess *s3v1alpha1.S3BucketAccess,
+	providerConfig, namespace string) error {
+	if r.Clients == nil || bktAccess.Status.Access == nil {
+		return nil
+	}
+	granted, err := arn.Parse(bktAccess.Status.Access.IdentityARN)
+	if err != nil || granted.AccountID == "" {
+		return nil
+	}
+	clients, err := r.Clients.Get(ctx, providerConfig, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to resolve AWS clients: %w", err)
+	}
+	account, err := clients.AccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if account != "" && account != granted.AccountID {
+		return fmt.Errorf("IAM user %s lives in account %s, but the provider config of the bucket acts in account %s",
+			bktAccess.Status.Access.IdentityARN, granted.AccountID, account)
+	}
+	return nil
+}
+
+// iamClient returns the IAM client of the provider config and namespace of a bucket
+func (r *S3BucketAccessReconciler) iamClient(ctx context.Context, providerConfig, namespace string) (*iam.IAM, error) {
+	if r.Clients == nil {
+		return r.IAMsvc, nil
+	}
+	clients, err := r.Clients.Get(ctx, providerConfig, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve AWS clients: %w", err)
+	}
+	if clients.IAM == nil {
+		return nil, fmt.Errorf("the provider of the bucket has no IAM, scoped access is only available on AWS")
+	}
+	return clients.IAM, nil
+}
+
+// bucketRefName returns the namespaced name of the S3Bucket referenced by an S3BucketAccess
+func bucketRefName(bktAccess *s3v1alpha1.S3BucketAccess) types.NamespacedName {
+	return types.NamespacedName{
+		Name:      bktAccess.Spec.BucketRef.Name,
+		Namespace: defaultString(bktAccess.Spec.BucketRef.Namespace, bktAccess.Namespace),
+	}
+}
+
+// bucketRefKey returns the namespace/name of the S3Bucket referenced by an S3BucketAccess
+func bucketRefKey(bktAccess *s3v1alpha1.S3BucketAccess) string {
+	return defaultString(bktAccess.Spec.BucketRef.Namespace, bktAccess.Namespace) + "/" + bktAccess.Spec.BucketRef.Name
+}
+
+// bucketAccessSecretName returns the name of the Secret holding the credentials of an S3BucketAccess
+func bucketAccessSecretName(bktAccess *s3v1alpha1.S3BucketAccess) string {
+	return defaultString(bktAccess.Spec.SecretName, fmt.Sprintf(accessSecretName, bktAccess.Name))
+}
+
+// updateAccessStatus sets the Ready condition with retry logic to handle conflicts.
+// Optional mutators can set additional status fields alongside the condition.
+func (r *S3BucketAccessReconciler) updateAccessStatus(ctx context.Context, bktAccess *s3v1alpha1.S3BucketAccess,
+	ready metav1.ConditionStatus, reason, message string, mutators ...func(*s3v1alpha1.S3BucketAccessStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &s3v1alpha1.S3BucketAccess{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(bktAccess), latest); err != nil {
+			return err
+		}
+
+		latest.Status.ObservedGeneration = latest.Generation
+		meta.SetStatusCondition(&latest.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionReady,
+			Status:             ready,
+			ObservedGeneration: latest.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+		for _, mutate := range mutators {
+			mutate(&latest.Status)
+		}
+		return r.Status().Update(ctx, latest)
+	})
+}
+
+// removeFinalizer removes the finalizer from the resource
+func (r *S3BucketAccessReconciler) removeFinalizer(ctx context.Context, bktAccess *s3v1alpha1.S3BucketAccess) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &s3v1alpha1.S3BucketAccess{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(bktAccess), latest); err != nil {
+			return err
+		}
+
+		controllerutil.RemoveFinalizer(latest, s3BucketAccessFinalizer)
+		return r.Update(ctx, latest)
+	})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3BucketAccess Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-access"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		s3bucketaccess := &s3v1alpha1.S3BucketAccess{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind S3BucketAccess")
+			err := k8sClient.Get(ctx, typeNamespacedName, s3bucketaccess)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &s3v1alpha1.S3BucketAccess{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: s3v1alpha1.S3BucketAccessSpec{
+						BucketRef: s3v1alpha1.BucketReference{Name: "test-resource"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &s3v1alpha1.S3BucketAccess{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance S3BucketAccess")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should successfully reconcile the resource", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &S3BucketAccessReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When checking cross-namespace access", func() {
+		bucket := &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "data"},
+			Spec:       s3v1alpha1.S3BucketSpec{AllowedAccessNamespaces: []string{"analytics"}},
+		}
+
+		It("should allow the bucket namespace and the listed namespaces only", func() {
+			Expect(namespaceAllowed(bucket, "data")).To(BeTrue())
+			Expect(namespaceAllowed(bucket, "analytics")).To(BeTrue())
+			Expect(namespaceAllowed(bucket, "web")).To(BeFalse())
+		})
+
+		It("should allow every namespace with a wildcard", func() {
+			wildcard := bucket.DeepCopy()
+			wildcard.Spec.AllowedAccessNamespaces = []string{"*"}
+			Expect(namespaceAllowed(wildcard, "web")).To(BeTrue())
+		})
+	})
+
+	Context("When a granted bucket is no longer usable", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			reconciler *S3BucketAccessReconciler
+			bucket     *s3v1alpha1.S3Bucket
+			bktAccess  *s3v1alpha1.S3BucketAccess
+			mu         sync.Mutex
+			actions    []string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			actions = nil
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			bucket = &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "data"},
+				Spec:       s3v1alpha1.S3BucketSpec{Name: "shared-bucket"},
+				Status:     s3v1alpha1.S3BucketStatus{State: s3v1alpha1.CREATED_STATE},
+			}
+			bktAccess = &s3v1alpha1.S3BucketAccess{
+				ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "analytics", Finalizers: []string{s3BucketAccessFinalizer}},
+				Spec: s3v1alpha1.S3BucketAccessSpec{
+					BucketRef: s3v1alpha1.BucketReference{Name: "shared", Namespace: "data"},
+				},
+				Status: s3v1alpha1.S3BucketAccessStatus{
+					BucketName: "shared-bucket",
+					Access:     &s3v1alpha1.AccessStatus{IdentityType: s3v1alpha1.IdentityTypeUser, SecretName: "reports-s3-access"},
+				},
+			}
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: bucketAccessSecretName(bktAccess), Namespace: "analytics"}}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(bucket, bktAccess, secret).
+				WithStatusSubresource(&s3v1alpha1.S3BucketAccess{}).Build()
+
+			// An IAM endpoint where the user of the grant is already gone
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.ParseForm()).To(Succeed())
+				mu.Lock()
+				actions = append(actions, req.Form.Get("Action"))
+				mu.Unlock()
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>NoSuchEntity</Code>` +
+					`<Message>The user cannot be found.</Message></Error><RequestId>1</RequestId></ErrorResponse>`))
+			}))
+			DeferCleanup(server.Close)
+			sess, err := session.NewSession(&aws.Config{
+				Endpoint:    aws.String(server.URL),
+				Region:      aws.String("us-east-1"),
+				Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			reconciler = &S3BucketAccessReconciler{Client: c, Scheme: scheme, IAMsvc: iam.New(sess)}
+		})
+
+		expectRevoked := func(reason string) {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(bktAccess)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actions).To(ContainElement("ListAccessKeys"))
+
+			err = c.Get(ctx, types.NamespacedName{Name: bucketAccessSecretName(bktAccess), Namespace: "analytics"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			latest := &s3v1alpha1.S3BucketAccess{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(bktAccess), latest)).To(Succeed())
+			Expect(latest.Status.Access).To(BeNil())
+			condition := meta.FindStatusCondition(latest.Status.Conditions, s3v1alpha1.ConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(reason))
+		}
+
+		It("should revoke the grant when the bucket no longer allows the namespace", func() {
+			expectRevoked("NamespaceNotAllowed")
+		})
+
+		It("should revoke the grant through the recorded account when the bucket is deleted", func() {
+			bktAccess.Status.BucketNamespace = "data"
+			Expect(c.Status().Update(ctx, bktAccess)).To(Succeed())
+			Expect(c.Delete(ctx, bucket)).To(Succeed())
+			expectRevoked("BucketNotFound")
+		})
+
+		It("should not accept a missing user when the account that granted access is unknown", func() {
+			Expect(c.Delete(ctx, bucket)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(bktAccess)})
+			Expect(err).To(MatchError(ContainSubstring("account that granted access")))
+			Expect(actions).To(Equal([]string{"GetUser"}))
+
+			latest := &s3v1alpha1.S3BucketAccess{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(bktAccess), latest)).To(Succeed())
+			Expect(latest.Status.Access).NotTo(BeNil())
+			Expect(c.Get(ctx, types.NamespacedName{Name: bucketAccessSecretName(bktAccess), Namespace: "analytics"}, &corev1.Secret{})).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,359 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	s3BucketClaimFinalizer = "s3bucketclaim.s3.acme.io/finalizer" // Finalizer string to be added to S3BucketClaim resources
+	// maxBucketNameLength is the longest bucket name S3 accepts
+	maxBucketNameLength = 63
+)
+
+// S3BucketClaimReconciler reconciles a S3BucketClaim object
+type S3BucketClaimReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketclaims/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketclaims/finalizers,verbs=update
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3bucketclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3buckets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile stamps out an S3Bucket from the class of the claim, with the allowed overrides
+// applied, and binds the claim to it once the bucket is created. Afterwards only the overrides
+// of the claim are applied to the bucket.
+func (r *S3BucketClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	log.Info("Reconciling S3BucketClaim", "NamespacedName", req.NamespacedName)
+
+	claim := &s3v1alpha1.S3BucketClaim{}
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+		log.Info("S3BucketClaim resource not found, ignoring since object must be deleted")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Release the bucket when the claim is being deleted
+	if !claim.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(claim, s3BucketClaimFinalizer) {
+			if err := r.releaseBucket(ctx, claim); err != nil {
+				log.Error(err, "Failed to release claimed bucket")
+				return ctrl.Result{}, err
+			}
+			if err := r.removeFinalizer(ctx, claim); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(claim, s3BucketClaimFinalizer) {
+		log.Info("Adding finalizer to S3BucketClaim", "BucketClaim", req.NamespacedName)
+		controllerutil.AddFinalizer(claim, s3BucketClaimFinalizer)
+		if err := r.Update(ctx, claim); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	class, err := r.resolveClass(ctx, claim)
+	if err != nil {
+		log.Info("Bucket class cannot be resolved", "Message", err.Error())
+		// The class watch requeues the claim once a matching class shows up
+		return ctrl.Result{}, r.updateClaimStatus(ctx, claim, s3v1alpha1.ClaimPending, "ClassNotFound", err.Error())
+	}
+
+	bucket := &s3v1alpha1.S3Bucket{}
+	err = r.Get(ctx, client.ObjectKeyFromObject(claim), bucket)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Like a StorageClass for volumes, the class defaults only apply when the bucket is created
+		spec, err := claimBucketSpec(class, claim, class.Spec.Defaults)
+		if err != nil {
+			log.Info("Bucket claim cannot be satisfied", "Message", err.Error())
+			return ctrl.Result{}, r.updateClaimStatus(ctx, claim, s3v1alpha1.ClaimFailed, "InvalidOverrides", err.Error())
+		}
+		bucket = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claim.Name,
+				Namespace: claim.Namespace,
+			},
+			Spec: spec,
+		}
+		bucket.Spec.Name = claimBucketName(class, claim)
+		if err := controllerutil.SetControllerReference(claim, bucket, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, bucket); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create S3Bucket: %w", err)
+		}
+	case err != nil:
+		return ctrl.Result{}, fmt.Errorf("failed to get S3Bucket: %w", err)
+	case !metav1.IsControlledBy(bucket, claim):
+		return ctrl.Result{}, r.updateClaimStatus(ctx, claim, s3v1alpha1.ClaimFailed, "BucketConflict",
+			fmt.Sprintf("S3Bucket %s/%s already exists and is not owned by the claim", bucket.Namespace, bucket.Name))
+	default:
+		// The bound bucket only follows the overrides of the claim
+		spec, err := claimBucketSpec(class, claim, bucket.Spec)
+		if err != nil {
+			log.Info("Bucket claim cannot be satisfied", "Message", err.Error())
+			return ctrl.Result{}, r.updateClaimStatus(ctx, claim, s3v1alpha1.ClaimFailed, "InvalidOverrides", err.Error())
+		}
+		if !equality.Semantic.DeepEqual(bucket.Spec, spec) {
+			bucket.Spec = spec
+			if err := r.Update(ctx, bucket); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update S3Bucket: %w", err)
+			}
+		}
+	}
+
+	phase, reason, message := s3v1alpha1.ClaimPending, "BucketNotReady",
+		fmt.Sprintf("S3Bucket %s is in state %q", bucket.Name, bucket.Status.State)
+	if bucket.Status.State == s3v1alpha1.CREATED_STATE {
+		phase, reason, message = s3v1alpha1.ClaimBound, "Bound", fmt.Sprintf("Bound to bucket %s", bucket.Spec.Name)
+	}
+
+	if err := r.updateClaimStatus(ctx, claim, phase, reason, message, func(status *s3v1alpha1.S3BucketClaimStatus) {
+		status.BucketRef = &s3v1alpha1.BucketReference{Name: bucket.Name, Namespace: bucket.Namespace}
+		status.BucketName = bucket.Spec.Name
+		status.ClassName = class.Name
+		status.ReclaimPolicy = defaultString(class.Spec.ReclaimPolicy, s3v1alpha1.ReclaimPolicyDelete)
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("S3BucketClaim reconciled", "BucketClaim", req.NamespacedName, "BucketName", bucket.Spec.Name, "Phase", phase)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *S3BucketClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&s3v1alpha1.S3BucketClaim{}).
+		Owns(&s3v1alpha1.S3Bucket{}).
+		Watches(&s3v1alpha1.S3BucketClass{}, handler.EnqueueRequestsFromMapFunc(r.claimsForClass)).
+		Named("s3bucketclaim").
+		Complete(r)
+}
+
+// claimsForClass maps an S3BucketClass to the claims that use it. Claims that have not been
+// bound to a class yet follow the default class
+func (r *S3BucketClaimReconciler) claimsForClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	claims := &s3v1alpha1.S3BucketClaimList{}
+	if err := r.List(ctx, claims); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list S3BucketClaim resources for class", "Class", obj.GetName())
+		return nil
+	}
+
+	isDefault := obj.GetAnnotations()[s3v1alpha1.DefaultBucketClassAnnotation] == "true"
+	requests := []reconcile.Request{}
+	for _, item := range claims.Items {
+		if item.Status.ClassName != "" {
+			if item.Status.ClassName == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+			}
+			continue
+		}
+		if item.Spec.ClassName == obj.GetName() || (item.Spec.ClassName == "" && isDefault) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+		}
+	}
+	return requests
+}
+
+// resolveClass returns the class the claim was bound to, the class named by the claim, or the
+// default class when none is named
+func (r *S3BucketClaimReconciler) resolveClass(ctx context.Context, claim *s3v1alpha1.S3BucketClaim) (*s3v1alpha1.S3BucketClass, error) {
+	if name := defaultString(claim.Status.ClassName, claim.Spec.ClassName); name != "" {
+		class := &s3v1alpha1.S3BucketClass{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name}, class); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("S3BucketClass %s not found", name)
+			}
+			return nil, fmt.Errorf("failed to get S3BucketClass %s: %w", name, err)
+		}
+		return class, nil
+	}
+
+	classes := &s3v1alpha1.S3BucketClassList{}
+	if err := r.List(ctx, classes); err != nil {
+		return nil, fmt.Errorf("failed to list S3BucketClass resources: %w", err)
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[s3v1alpha1.DefaultBucketClassAnnotation] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no className set and no default S3BucketClass found")
+}
+
+// claimBucketSpec merges the allowed overrides of the claim into base, the class defaults for new
+// buckets or the spec of the bound bucket
+func claimBucketSpec(class *s3v1alpha1.S3BucketClass, claim *s3v1alpha1.S3BucketClaim, base s3v1alpha1.S3BucketSpec) (s3v1alpha1.S3BucketSpec, error) {
+	spec := *base.DeepCopy()
+	if claim.Spec.Overrides == nil || len(claim.Spec.Overrides.Raw) == 0 {
+		return spec, nil
+	}
+
+	overrides := map[string]json.RawMessage{}
+	if err := json.Unmarshal(claim.Spec.Overrides.Raw, &overrides); err != nil {
+		return spec, fmt.Errorf("overrides must be an object: %w", err)
+	}
+
+	allowed := map[string]bool{}
+	for _, field := range class.Spec.AllowedOverrides {
+		allowed[field] = true
+	}
+	var denied []string
+	for field := range overrides {
+		if !allowed[field] {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return spec, fmt.Errorf("S3BucketClass %s does not allow overriding %s", class.Name, strings.Join(denied, ", "))
+	}
+
+	// Decode the overrides over the defaults so only the overridden fields change
+	decoder := json.NewDecoder(bytes.NewReader(claim.Spec.Overrides.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return spec, fmt.Errorf("invalid overrides: %w", err)
+	}
+	return spec, nil
+}
+
+// claimBucketName generates a bucket name for a claim from the class prefix, the claim namespace
+// and name, and a suffix of its UID that keeps it unique across clusters
+func claimBucketName(class *s3v1alpha1.S3BucketClass, claim *s3v1alpha1.S3BucketClaim) string {
+	suffix := strings.ReplaceAll(string(claim.UID), "-", "")
+	if len(suffix) > 8 {
+		suffix = suffix[:8]
+	}
+
+	name := strings.ToLower(class.Spec.BucketNamePrefix + claim.Namespace + "-" + claim.Name)
+	name = strings.ReplaceAll(name, ".", "-")
+	if limit := maxBucketNameLength - len(suffix) - 1; len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	return name + "-" + suffix
+}
+
+// releaseBucket lets the bound S3Bucket go with the claim, or orphans it when the class retains buckets.
+// The policy recorded when the claim was reconciled wins over the current class, which may have
+// been deleted or replaced as the default since
+func (r *S3BucketClaimReconciler) releaseBucket(ctx context.Context, claim *s3v1alpha1.S3BucketClaim) error {
+	log := logf.FromContext(ctx)
+
+	policy := claim.Status.ReclaimPolicy
+	if policy == "" {
+		class, err := r.resolveClass(ctx, claim)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the reclaim policy of the claimed bucket: %w", err)
+		}
+		policy = defaultString(class.Spec.ReclaimPolicy, s3v1alpha1.ReclaimPolicyDelete)
+	}
+	if policy != s3v1alpha1.ReclaimPolicyRetain {
+		// Garbage collection deletes the owned S3Bucket, which in turn deletes the bucket
+		return nil
+	}
+
+	log.Info("Retaining claimed bucket", "BucketClaim", client.ObjectKeyFromObject(claim))
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		bucket := &s3v1alpha1.S3Bucket{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(claim), bucket); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(bucket, claim) {
+			return nil
+		}
+		if err := controllerutil.RemoveControllerReference(claim, bucket, r.Scheme); err != nil {
+			return err
+		}
+		return r.Update(ctx, bucket)
+	})
+}
+
+// updateClaimStatus sets the phase and Bound condition with retry logic to handle conflicts.
+// Optional mutators can set additional status fields alongside them.
+func (r *S3BucketClaimReconciler) updateClaimStatus(ctx context.Context, claim *s3v1alpha1.S3BucketClaim,
+	phase, reason, message string, mutators ...func(*s3v1alpha1.S3BucketClaimStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &s3v1alpha1.S3BucketClaim{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(claim), latest); err != nil {
+			return err
+		}
+
+		bound := metav1.ConditionFalse
+		if phase == s3v1alpha1.ClaimBound {
+			bound = metav1.ConditionTrue
+		}
+
+		latest.Status.Phase = phase
+		latest.Status.ObservedGeneration = latest.Generation
+		meta.SetStatusCondition(&latest.Status.Conditions, metav1.Condition{
+			Type:               s3v1alpha1.ConditionBound,
+			Status:             bound,
+			ObservedGeneration: latest.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+		for _, mutate := range mutators {
+			mutate(&latest.Status)
+		}
+		return r.Status().Update(ctx, latest)
+	})
+}
+
+// removeFinalizer removes the finalizer from the resource
+func (r *S3BucketClaimReconciler) removeFinalizer(ctx context.Context, claim *s3v1alpha1.S3BucketClaim) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &s3v1alpha1.S3BucketClaim{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(claim), latest); err != nil {
+			return err
+		}
+
+		controllerutil.RemoveFinalizer(latest, s3BucketClaimFinalizer)
+		return r.Update(ctx, latest)
+	})
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3BucketClaim Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-claim"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		s3bucketclaim := &s3v1alpha1.S3BucketClaim{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind S3BucketClaim")
+			err := k8sClient.Get(ctx, typeNamespacedName, s3bucketclaim)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &s3v1alpha1.S3BucketClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: s3v1alpha1.S3BucketClaimSpec{
+						ClassName: "standard",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &s3v1alpha1.S3BucketClaim{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance S3BucketClaim")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should successfully reconcile the resource", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &S3BucketClaimReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When building the bucket of a claim", func() {
+		class := &s3v1alpha1.S3BucketClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+			Spec: s3v1alpha1.S3BucketClassSpec{
+				Defaults: s3v1alpha1.S3BucketSpec{
+					Region:      "eu-west-1",
+					DriftPolicy: s3v1alpha1.DriftPolicyEnforce,
+				},
+				AllowedOverrides: []string{"requesterPays", "defaultStorageClass"},
+				BucketNamePrefix: "acme-",
+			},
+		}
+		claimWith := func(overrides string) *s3v1alpha1.S3BucketClaim {
+			claim := &s3v1alpha1.S3BucketClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "assets", Namespace: "web", UID: "0b6e2f4a-9c1d-4e8f-a2b3-c4d5e6f7a8b9"},
+			}
+			if overrides != "" {
+				claim.Spec.Overrides = &runtime.RawExtension{Raw: []byte(overrides)}
+			}
+			return claim
+		}
+
+		It("should use the class defaults without overrides", func() {
+			spec, err := claimBucketSpec(class, claimWith(""), class.Spec.Defaults)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec).To(Equal(class.Spec.Defaults))
+		})
+
+		It("should apply allowed overrides on top of the defaults", func() {
+			spec, err := claimBucketSpec(class, claimWith(`{"requesterPays":true,"defaultStorageClass":"STANDARD_IA"}`), class.Spec.Defaults)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.Region).To(Equal("eu-west-1"))
+			Expect(spec.DriftPolicy).To(Equal(s3v1alpha1.DriftPolicyEnforce))
+			Expect(spec.RequesterPays).To(BeTrue())
+			Expect(spec.DefaultStorageClass).To(Equal("STANDARD_IA"))
+		})
+
+		It("should reject overrides the class does not allow", func() {
+			_, err := claimBucketSpec(class, claimWith(`{"region":"us-east-1","locked":true}`), class.Spec.Defaults)
+			Expect(err).To(MatchError(ContainSubstring("locked, region")))
+		})
+
+		It("should generate a unique, valid bucket name", func() {
+			Expect(claimBucketName(class, claimWith(""))).To(Equal("acme-web-assets-0b6e2f4a"))
+
+			long := claimWith("")
+			long.Name = "a-very-long-claim-name-that-would-not-fit-into-an-s3-bucket-name"
+			name := claimBucketName(class, long)
+			Expect(len(name)).To(BeNumerically("<=", maxBucketNameLength))
+			Expect(name).To(HaveSuffix("-0b6e2f4a"))
+		})
+	})
+
+	Context("When the class of a bound claim changes", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			reconciler *S3BucketClaimReconciler
+			claim      *s3v1alpha1.S3BucketClaim
+			standard   *s3v1alpha1.S3BucketClass
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			standard = &s3v1alpha1.S3BucketClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "standard",
+					Annotations: map[string]string{s3v1alpha1.DefaultBucketClassAnnotation: "true"},
+				},
+				Spec: s3v1alpha1.S3BucketClassSpec{
+					Defaults:         s3v1alpha1.S3BucketSpec{Region: "eu-west-1"},
+					AllowedOverrides: []string{"requesterPays"},
+				},
+			}
+			claim = &s3v1alpha1.S3BucketClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "assets",
+					Namespace:  "web",
+					UID:        "0b6e2f4a-9c1d-4e8f-a2b3-c4d5e6f7a8b9",
+					Finalizers: []string{s3BucketClaimFinalizer},
+				},
+				Spec: s3v1alpha1.S3BucketClaimSpec{Overrides: &runtime.RawExtension{Raw: []byte(`{"requesterPays":true}`)}},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(standard, claim).
+				WithStatusSubresource(&s3v1alpha1.S3BucketClaim{}).Build()
+			reconciler = &S3BucketClaimReconciler{Client: c, Scheme: scheme}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		latestBucket := func() *s3v1alpha1.S3Bucket {
+			bucket := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(claim), bucket)).To(Succeed())
+			return bucket
+		}
+
+		It("should stamp out the bucket from the class once and record the class", func() {
+			bucket := latestBucket()
+			Expect(bucket.Spec.Region).To(Equal("eu-west-1"))
+			Expect(bucket.Spec.RequesterPays).To(BeTrue())
+			Expect(bucket.Spec.Name).To(Equal("web-assets-0b6e2f4a"))
+
+			latest := &s3v1alpha1.S3BucketClaim{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(claim), latest)).To(Succeed())
+			Expect(latest.Status.ClassName).To(Equal("standard"))
+
+			By("changing the class defaults")
+			standard.Spec.Defaults.Region = "us-east-1"
+			standard.Spec.Defaults.Versioning = true
+			Expect(c.Update(ctx, standard)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latestBucket().Spec.Region).To(Equal("eu-west-1"))
+			Expect(latestBucket().Spec.Versioning).To(BeFalse())
+		})
+
+		It("should keep applying the overrides of the claim", func() {
+			latest := &s3v1alpha1.S3BucketClaim{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(claim), latest)).To(Succeed())
+			latest.Spec.Overrides = &runtime.RawExtension{Raw: []byte(`{"requesterPays":false}`)}
+			Expect(c.Update(ctx, latest)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latestBucket().Spec.RequesterPays).To(BeFalse())
+		})
+
+		It("should only enqueue claims bound to the class", func() {
+			archive := &s3v1alpha1.S3BucketClass{ObjectMeta: metav1.ObjectMeta{
+				Name:        "archive",
+				Annotations: map[string]string{s3v1alpha1.DefaultBucketClassAnnotation: "true"},
+			}}
+			Expect(reconciler.claimsForClass(ctx, archive)).To(BeEmpty())
+			Expect(reconciler.claimsForClass(ctx, standard)).To(HaveLen(1))
+		})
+	})
+
+	Context("When releasing the bucket of a deleted claim", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			reconciler *S3BucketClaimReconciler
+			claim      *s3v1alpha1.S3BucketClaim
+			bucket     *s3v1alpha1.S3Bucket
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			claim = &s3v1alpha1.S3BucketClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "assets", Namespace: "web", UID: "0b6e2f4a-9c1d-4e8f-a2b3-c4d5e6f7a8b9"},
+				Spec:       s3v1alpha1.S3BucketClaimSpec{ClassName: "archive"},
+			}
+			bucket = &s3v1alpha1.S3Bucket{ObjectMeta: metav1.ObjectMeta{Name: "assets", Namespace: "web"}}
+			Expect(controllerutil.SetControllerReference(claim, bucket, scheme)).To(Succeed())
+			// The class of the claim has been deleted
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(bucket).Build()
+			reconciler = &S3BucketClaimReconciler{Client: c, Scheme: scheme}
+		})
+
+		It("should retain the bucket with the recorded policy", func() {
+			claim.Status.ReclaimPolicy = s3v1alpha1.ReclaimPolicyRetain
+			Expect(reconciler.releaseBucket(ctx, claim)).To(Succeed())
+
+			latest := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(bucket), latest)).To(Succeed())
+			Expect(metav1.IsControlledBy(latest, claim)).To(BeFalse())
+		})
+
+		It("should keep the claim when the policy cannot be resolved", func() {
+			Expect(reconciler.releaseBucket(ctx, claim)).To(MatchError(ContainSubstring("S3BucketClass archive not found")))
+
+			latest := &s3v1alpha1.S3Bucket{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(bucket), latest)).To(Succeed())
+			Expect(metav1.IsControlledBy(latest, claim)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/s3client"
+)
+
+// S3ProviderConfigReconciler reconciles a S3ProviderConfig object
+type S3ProviderConfigReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=s3.acme.io,resources=s3providerconfigs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile tracks the credentials Secret of an S3ProviderConfig and records when it rotates.
+// The AWS clients of the config are rebuilt on their next use once the Secret changed.
+func (r *S3ProviderConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	log.Info("Reconciling S3ProviderConfig", "Name", req.Name)
+
+	config := &s3v1alpha1.S3ProviderConfig{}
+	if err := r.Get(ctx, req.NamespacedName, config); err != nil {
+		log.Info("S3ProviderConfig resource not found, ignoring since object must be deleted")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ref := config.Spec.CredentialsSecretRef
+	if ref == nil {
+		return ctrl.Result{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The Secret watch requeues the config once it shows up
+			log.Info("Credentials Secret not found", "Secret", ref.Namespace+"/"+ref.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get credentials Secret: %w", err)
+	}
+
+	version := s3client.CredentialsVersion(secret)
+	if version == config.Status.CredentialsVersion {
+		return ctrl.Result{}, nil
+	}
+
+	// The first observation is not a rotation
+	rotated := config.Status.CredentialsVersion != ""
+	now := metav1.Now()
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &s3v1alpha1.S3ProviderConfig{}
+		if err := r.Get(ctx, req.NamespacedName, latest); err != nil {
+			return err
+		}
+		latest.Status.CredentialsVersion = version
+		if rotated {
+			latest.Status.LastRotationTime = &now
+		}
+		return r.Status().Update(ctx, latest)
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update S3ProviderConfig status: %w", err)
+	}
+
+	if rotated {
+		log.Info("Provider config credentials rotated", "Name", config.Name)
+		s3client.RecordRotation(config.Name, now.Time)
+		r.Recorder.Eventf(config, corev1.EventTypeNormal, "CredentialsRotated",
+			"Credentials Secret %s/%s changed, AWS clients are rebuilt on next use", ref.Namespace, ref.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *S3ProviderConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&s3v1alpha1.S3ProviderConfig{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.configsForSecret)).
+		Named("s3providerconfig").
+		Complete(r)
+}
+
+// configsForSecret maps a Secret to the provider configs reading credentials from it
+func (r *S3ProviderConfigReconciler) configsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	configs := &s3v1alpha1.S3ProviderConfigList{}
+	if err := r.List(ctx, configs); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list S3ProviderConfig resources for Secret", "Secret", obj.GetName())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, item := range configs.Items {
+		ref := item.Spec.CredentialsSecretRef
+		if ref != nil && ref.Name == obj.GetName() && ref.Namespace == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("S3ProviderConfig Controller", func() {
+	Context("When the credentials Secret rotates", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			recorder   *record.FakeRecorder
+			reconciler *S3ProviderConfigReconciler
+			secret     *corev1.Secret
+			request    reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "s3-acme"},
+				Data: map[string][]byte{
+					s3v1alpha1.CredentialsAccessKeyIDKey:     []byte("first"),
+					s3v1alpha1.CredentialsSecretAccessKeyKey: []byte("first-secret"),
+				},
+			}
+			config := &s3v1alpha1.S3ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+				Spec: s3v1alpha1.S3ProviderConfigSpec{
+					CredentialsSecretRef: &s3v1alpha1.SecretReference{Name: "tenant-a", Namespace: "s3-acme"},
+				},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, config).
+				WithStatusSubresource(&s3v1alpha1.S3ProviderConfig{}).Build()
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &S3ProviderConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+			request = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)}
+		})
+
+		getConfig := func() *s3v1alpha1.S3ProviderConfig {
+			config := &s3v1alpha1.S3ProviderConfig{}
+			Expect(c.Get(ctx, request.NamespacedName, config)).To(Succeed())
+			return config
+		}
+
+		It("should record the first observation without a rotation", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			config := getConfig()
+			Expect(config.Status.CredentialsVersion).NotTo(BeEmpty())
+			Expect(config.Status.LastRotationTime).To(BeNil())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should record a rotation when the keys change", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("changing only the labels of the Secret")
+			secret.Labels = map[string]string{"team": "a"}
+			Expect(c.Update(ctx, secret)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getConfig().Status.LastRotationTime).To(BeNil())
+
+			By("rotating the keys")
+			secret.Data[s3v1alpha1.CredentialsAccessKeyIDKey] = []byte("second")
+			Expect(c.Update(ctx, secret)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(getConfig().Status.LastRotationTime).NotTo(BeNil())
+			Expect(recorder.Events).To(Receive(ContainSubstring("CredentialsRotated")))
+		})
+
+		It("should map the Secret to the configs referencing it", func() {
+			Expect(reconciler.configsForSecret(ctx, secret)).To(ConsistOf(request))
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "default"}}
+			Expect(reconciler.configsForSecret(ctx, other)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// listTimeout bounds the listing of S3Buckets during a scrape
+const listTimeout = 10 * time.Second
+
+var (
+	bucketsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "buckets"),
+		"Number of S3Buckets by namespace and state",
+		[]string{"namespace", "state"}, nil)
+
+	bucketConditionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bucket_conditions"),
+		"Number of S3Buckets by namespace, condition type and condition status",
+		[]string{"namespace", "condition", "status"}, nil)
+
+	bucketSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bucket_size_bytes"),
+		"Total size of the objects of a bucket, as last collected by the operator",
+		[]string{"namespace", "name", "bucket"}, nil)
+
+	bucketObjectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bucket_objects"),
+		"Number of objects in a bucket, as last collected by the operator",
+		[]string{"namespace", "name", "bucket"}, nil)
+)
+
+// bucketCollector counts the S3Buckets of the informer cache at scrape time, so deleted
+// buckets and namespaces never leave stale series behind
+type bucketCollector struct {
+	reader client.Reader
+}
+
+// RegisterBucketCollector registers the gauges of S3Buckets per state and condition, and of the
+// usage of each bucket, read through reader
+func RegisterBucketCollector(reader client.Reader) error {
+	return metrics.Registry.Register(&bucketCollector{reader: reader})
+}
+
+// Describe implements prometheus.Collector
+func (c *bucketCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bucketsDesc
+	ch <- bucketConditionsDesc
+	ch <- bucketSizeDesc
+	ch <- bucketObjectsDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *bucketCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
+
+	buckets := &s3v1alpha1.S3BucketList{}
+	if err := c.reader.List(ctx, buckets); err != nil {
+		ch <- prometheus.NewInvalidMetric(bucketsDesc, err)
+		return
+	}
+
+	type stateKey struct {
+		namespace, state string
+	}
+	type conditionKey struct {
+		namespace, condition string
+		status               metav1.ConditionStatus
+	}
+	states := map[stateKey]int{}
+	conditions := map[conditionKey]int{}
+	for _, s3bkt := range buckets.Items {
+		state := s3bkt.Status.State
+		if state == "" {
+			state = s3v1alpha1.PENDING_STATE
+		}
+		states[stateKey{s3bkt.Namespace, state}]++
+		for _, condition := range s3bkt.Status.Conditions {
+			conditions[conditionKey{s3bkt.Namespace, condition.Type, condition.Status}]++
+		}
+		if usage := s3bkt.Status.Usage; usage != nil {
+			ch <- prometheus.MustNewConstMetric(bucketSizeDesc, prometheus.GaugeValue, float64(usage.SizeBytes),
+				s3bkt.Namespace, s3bkt.Name, s3bkt.Spec.Name)
+			ch <- prometheus.MustNewConstMetric(bucketObjectsDesc, prometheus.GaugeValue, float64(usage.ObjectCount),
+				s3bkt.Namespace, s3bkt.Name, s3bkt.Spec.Name)
+		}
+	}
+
+	for key, count := range states {
+		ch <- prometheus.MustNewConstMetric(bucketsDesc, prometheus.GaugeValue, float64(count), key.namespace, key.state)
+	}
+	for key, count := range conditions {
+		ch <- prometheus.MustNewConstMetric(bucketConditionsDesc, prometheus.GaugeValue, float64(count),
+			key.namespace, key.condition, string(key.status))
+	}
+}
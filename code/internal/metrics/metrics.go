@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the operator. They are registered with the
+// controller-runtime metrics registry and served by the metrics endpoint of the manager.
+package metrics
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// namespace prefixes the names of every metric of the operator
+const namespace = "kube_s3_operator"
+
+// Phases of S3Bucket reconciles
+const (
+	PhaseCreate = "create"
+	PhaseSync   = "sync"
+	PhaseRepair = "repair"
+	PhaseDelete = "delete"
+)
+
+// Results of reconciles
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// codeOK is the code label of S3 API calls that succeeded
+const codeOK = "OK"
+
+var (
+	// S3RequestDuration observes the duration of S3 API calls, retries included
+	S3RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "s3_api_request_duration_seconds",
+		Help:      "Duration of S3 API calls, retries included, by operation and region",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "region"})
+
+	// S3Requests counts S3 API calls by outcome; code is the AWS error code, or OK
+	S3Requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_api_requests_total",
+		Help:      "Number of S3 API calls by operation, region and AWS error code, OK for successful calls",
+	}, []string{"operation", "region", "code"})
+
+	// BucketReconciles counts the reconciles of S3Buckets by phase and result
+	BucketReconciles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bucket_reconcile_total",
+		Help:      "Number of S3Bucket reconciles by phase (create, sync, repair, delete) and result (success, error)",
+	}, []string{"phase", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(S3RequestDuration, S3Requests, BucketReconciles)
+}
+
+// S3Handler records the S3 API calls of a client. It belongs to the Complete handlers, which
+// run once per call after the last retry
+var S3Handler = request.NamedHandler{
+	Name: "kube-s3-operator.metrics.S3Handler",
+	Fn:   observeS3Request,
+}
+
+// observeS3Request records one S3 API call
+func observeS3Request(r *request.Request) {
+	operation, region := r.Operation.Name, aws.StringValue(r.Config.Region)
+
+	code := codeOK
+	if r.Error != nil {
+		code = "Unknown"
+		if aerr, ok := r.Error.(awserr.Error); ok {
+			code = aerr.Code()
+		}
+	}
+	S3Requests.WithLabelValues(operation, region, code).Inc()
+	S3RequestDuration.WithLabelValues(operation, region).Observe(time.Since(r.Time).Seconds())
+}
+
+// ObserveBucketReconcile records the result of a phase of an S3Bucket reconcile
+func ObserveBucketReconcile(phase string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	BucketReconciles.WithLabelValues(phase, result).Inc()
+}
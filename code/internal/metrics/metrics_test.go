@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("Metrics", func() {
+	Context("S3 API calls", func() {
+		newRequest := func(operation string, err error) *request.Request {
+			return &request.Request{
+				Operation: &request.Operation{Name: operation},
+				Config:    aws.Config{Region: aws.String("eu-west-1")},
+				Time:      time.Now().Add(-time.Second),
+				Error:     err,
+			}
+		}
+
+		It("should count calls by AWS error code", func() {
+			ok := S3Requests.WithLabelValues("HeadBucket", "eu-west-1", "OK")
+			missing := S3Requests.WithLabelValues("HeadBucket", "eu-west-1", "NoSuchBucket")
+			unknown := S3Requests.WithLabelValues("HeadBucket", "eu-west-1", "Unknown")
+			before := []float64{testutil.ToFloat64(ok), testutil.ToFloat64(missing), testutil.ToFloat64(unknown)}
+
+			observeS3Request(newRequest("HeadBucket", nil))
+			observeS3Request(newRequest("HeadBucket", awserr.New("NoSuchBucket", "not found", nil)))
+			observeS3Request(newRequest("HeadBucket", errors.New("connection reset")))
+
+			Expect(testutil.ToFloat64(ok)).To(Equal(before[0] + 1))
+			Expect(testutil.ToFloat64(missing)).To(Equal(before[1] + 1))
+			Expect(testutil.ToFloat64(unknown)).To(Equal(before[2] + 1))
+		})
+
+		It("should observe the duration of calls", func() {
+			observeS3Request(newRequest("PutBucketTagging", nil))
+
+			Expect(testutil.CollectAndCount(S3RequestDuration, namespace+"_s3_api_request_duration_seconds")).
+				To(BeNumerically(">=", 1))
+		})
+	})
+
+	It("should count reconciles by phase and result", func() {
+		success := BucketReconciles.WithLabelValues(PhaseSync, ResultSuccess)
+		failure := BucketReconciles.WithLabelValues(PhaseSync, ResultError)
+		before := []float64{testutil.ToFloat64(success), testutil.ToFloat64(failure)}
+
+		ObserveBucketReconcile(PhaseSync, nil)
+		ObserveBucketReconcile(PhaseSync, errors.New("access denied"))
+
+		Expect(testutil.ToFloat64(success)).To(Equal(before[0] + 1))
+		Expect(testutil.ToFloat64(failure)).To(Equal(before[1] + 1))
+	})
+
+	It("should report S3Buckets per namespace, state and condition, and their usage", func() {
+		scheme := runtime.NewScheme()
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+		newBucket := func(namespace, name, state string, conditions ...metav1.Condition) *s3v1alpha1.S3Bucket {
+			return &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       s3v1alpha1.S3BucketSpec{Name: namespace + "-" + name},
+				Status:     s3v1alpha1.S3BucketStatus{State: state, Conditions: conditions},
+			}
+		}
+		ready := metav1.Condition{Type: s3v1alpha1.ConditionReady, Status: metav1.ConditionTrue}
+		logs := newBucket("team-a", "logs", s3v1alpha1.CREATED_STATE, ready)
+		logs.Status.Usage = &s3v1alpha1.BucketUsage{SizeBytes: 2048, ObjectCount: 3, Source: s3v1alpha1.UsageSourceListing}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			logs,
+			newBucket("team-a", "data", s3v1alpha1.CREATED_STATE, ready),
+			newBucket("team-b", "new", ""),
+		).Build()
+
+		expected := `
+# HELP kube_s3_operator_buckets Number of S3Buckets by namespace and state
+# TYPE kube_s3_operator_buckets gauge
+kube_s3_operator_buckets{namespace="team-a",state="CREATED"} 2
+kube_s3_operator_buckets{namespace="team-b",state="PENDING"} 1
+# HELP kube_s3_operator_bucket_conditions Number of S3Buckets by namespace, condition type and condition status
+# TYPE kube_s3_operator_bucket_conditions gauge
+kube_s3_operator_bucket_conditions{condition="Ready",namespace="team-a",status="True"} 2
+# HELP kube_s3_operator_bucket_size_bytes Total size of the objects of a bucket, as last collected by the operator
+# TYPE kube_s3_operator_bucket_size_bytes gauge
+kube_s3_operator_bucket_size_bytes{bucket="team-a-logs",name="logs",namespace="team-a"} 2048
+# HELP kube_s3_operator_bucket_objects Number of objects in a bucket, as last collected by the operator
+# TYPE kube_s3_operator_bucket_objects gauge
+kube_s3_operator_bucket_objects{bucket="team-a-logs",name="logs",namespace="team-a"} 3
+`
+		Expect(testutil.CollectAndCompare(&bucketCollector{reader: c}, strings.NewReader(expected))).To(Succeed())
+	})
+})
@@ -0,0 +1,310 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package azure manages Azure Blob Storage containers through the blob service REST API,
+// and their immutability and lifecycle through Azure Resource Manager.
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+const (
+	// storageAPIVersion is the blob service REST API version, supported by Azurite
+	storageAPIVersion = "2021-12-02"
+	// metadataHeaderPrefix prefixes the container metadata headers
+	metadataHeaderPrefix = "x-ms-meta-"
+)
+
+// Keys of the connection details published for the containers
+const (
+	AccountURLKey   = "AccountURL"
+	accountNameKey  = "AZURE_STORAGE_ACCOUNT"
+	accountURLKey   = "AZURE_STORAGE_ACCOUNT_URL"
+	containerKey    = "AZURE_STORAGE_CONTAINER"
+	containerURLKey = "AZURE_STORAGE_CONTAINER_URL"
+	sasTokenKey     = "AZURE_STORAGE_SAS_TOKEN"
+	sasURLKey       = "AZURE_STORAGE_CONTAINER_SAS_URL"
+	sasExpiryKey    = "AZURE_STORAGE_SAS_EXPIRY"
+)
+
+// supportedFields are the spec fields mapped to container settings
+var supportedFields = []string{"tags", "versioning", "locked", "retentionDays", "lifecycle"}
+
+// managedFields need Azure Resource Manager
+var managedFields = []string{"versioning", "locked", "retentionDays", "lifecycle"}
+
+// Config describes how to reach a storage account
+type Config struct {
+	// AccountName is the storage account
+	AccountName string
+	// AccountKey is the base64 encoded shared key of the storage account
+	AccountKey string
+	// Endpoint is the blob endpoint of the account, https://<account>.blob.core.windows.net by
+	// default. Path-style endpoints such as Azurite's http://127.0.0.1:10000/devstoreaccount1 work too
+	Endpoint string
+	// Management enables immutability policies and lifecycle management, and reports whether
+	// the account keeps blob versions
+	Management *ManagementConfig
+	// HTTPClient is the base client of the requests, e.g. trusting a custom CA bundle
+	HTTPClient *http.Client
+}
+
+// Manager manages the containers of one storage account
+type Manager struct {
+	account    string
+	key        []byte
+	endpoint   string
+	client     *http.Client
+	management *management
+}
+
+var (
+	_ provider.BucketManager    = &Manager{}
+	_ provider.Checker          = &Manager{}
+	_ provider.CredentialIssuer = &Manager{}
+)
+
+// New returns a Manager for config
+func New(config Config) (*Manager, error) {
+	key, err := base64.StdEncoding.DecodeString(config.AccountKey)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid shared key for storage account %s", config.AccountName)
+	}
+	m := &Manager{
+		account:  config.AccountName,
+		key:      key,
+		endpoint: strings.TrimSuffix(config.Endpoint, "/"),
+		client:   config.HTTPClient,
+	}
+	if m.endpoint == "" {
+		m.endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", config.AccountName)
+	}
+	if m.client == nil {
+		m.client = http.DefaultClient
+	}
+	if config.Management != nil {
+		m.management = newManagement(*config.Management, config.AccountName, m.client)
+	}
+	return m, nil
+}
+
+// APIError is an error response of the blob service or of Azure Resource Manager
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Azure API returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// isNotFound reports whether err is an Azure 404 response
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Ensure creates the container when it does not exist and applies the spec to it
+func (m *Manager) Ensure(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*provider.BucketState, error) {
+	log := logf.FromContext(ctx)
+	container := s3bkt.Spec.Name
+	unsupported := provider.UnsupportedFields(s3bkt.Spec, supportedFields...)
+
+	_, err := m.containerRequest(ctx, http.MethodHead, container, nil, nil)
+	switch {
+	case isNotFound(err):
+		log.Info("Creating Azure container", "BucketName", container)
+		if _, err := m.containerRequest(ctx, http.MethodPut, container, nil, metadataHeaders(s3bkt.Spec.Tags)); err != nil {
+			return nil, fmt.Errorf("Azure Create Container API call failed: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("Azure Get Container Properties API call failed: %w", err)
+	default:
+		// Setting metadata replaces all of it, removing tags no longer declared
+		query := url.Values{"comp": {"metadata"}}
+		if _, err := m.containerRequest(ctx, http.MethodPut, container, query, metadataHeaders(s3bkt.Spec.Tags)); err != nil {
+			return nil, fmt.Errorf("Azure Set Container Metadata API call failed: %w", err)
+		}
+	}
+
+	if m.management == nil {
+		// Without Azure Resource Manager only the container itself is managed
+		requested := provider.UnsupportedFields(s3bkt.Spec)
+		for _, field := range managedFields {
+			if slices.Contains(requested, field) {
+				unsupported = append(unsupported, field)
+			}
+		}
+	} else {
+		if s3bkt.Spec.Versioning {
+			// Versioning is shared by every container of the account; it is only reported
+			enabled, err := m.management.versioningEnabled(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !enabled {
+				unsupported = append(unsupported, "versioning")
+			}
+		}
+		if err := m.management.applyImmutabilityPolicy(ctx, container, s3bkt.Spec); err != nil {
+			return nil, err
+		}
+		mapped, err := m.management.applyLifecycle(ctx, container, s3bkt.Spec.Lifecycle)
+		if err != nil {
+			return nil, err
+		}
+		if !mapped {
+			unsupported = append(unsupported, "lifecycle.transitions")
+		}
+		if s3bkt.Spec.Locked && s3bkt.Spec.RetentionDays == 0 {
+			// Azure locks immutability policies, which need a retention period
+			unsupported = append(unsupported, "locked")
+		}
+	}
+	sort.Strings(unsupported)
+
+	return &provider.BucketState{
+		Location:          s3bkt.Spec.Region,
+		URL:               m.endpoint + "/" + container,
+		Unsupported:       unsupported,
+		ConnectionDetails: map[string]string{AccountURLKey: m.endpoint},
+		// The shared key grants access to every container of the account; applications get a
+		// shared access signature of their container from IssueCredentials instead
+		Secret: map[string][]byte{
+			accountNameKey:  []byte(m.account),
+			accountURLKey:   []byte(m.endpoint),
+			containerKey:    []byte(container),
+			containerURLKey: []byte(m.endpoint + "/" + container),
+		},
+	}, nil
+}
+
+// IssueCredentials signs a shared access signature of the container, valid for sasLifetime
+func (m *Manager) IssueCredentials(s3bkt *s3v1alpha1.S3Bucket, now time.Time) (map[string][]byte, time.Time, error) {
+	protocol := "https"
+	if strings.HasPrefix(m.endpoint, "http://") {
+		protocol = "https,http"
+	}
+	expiry := now.Add(sasLifetime).UTC().Truncate(time.Second)
+	token := containerSAS(m.account, m.key, s3bkt.Spec.Name, now.Add(-sasClockSkew), expiry, protocol)
+	return map[string][]byte{
+		sasTokenKey:  []byte(token),
+		sasURLKey:    []byte(m.endpoint + "/" + url.PathEscape(s3bkt.Spec.Name) + "?" + token),
+		sasExpiryKey: []byte(expiry.Format(time.RFC3339)),
+	}, expiry, nil
+}
+
+// Delete deletes the container and every blob in it
+func (m *Manager) Delete(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+	log.Info("Deleting Azure container", "BucketName", s3bkt.Spec.Name)
+
+	if m.management != nil {
+		if err := m.management.removeLifecycle(ctx, s3bkt.Spec.Name); err != nil {
+			return err
+		}
+	}
+	_, err := m.containerRequest(ctx, http.MethodDelete, s3bkt.Spec.Name, nil, nil)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("Azure Delete Container API call failed: %w", err)
+	}
+	return nil
+}
+
+// metadataHeaders converts tags to container metadata headers, whose names must be C# identifiers
+func metadataHeaders(tags map[string]string) http.Header {
+	header := http.Header{}
+	for key, value := range tags {
+		name := strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return unicode.ToLower(r)
+			}
+			return '_'
+		}, key)
+		if name == "" || unicode.IsDigit(rune(name[0])) {
+			name = "_" + name
+		}
+		header.Set(metadataHeaderPrefix+name, value)
+	}
+	return header
+}
+
+// Check lists at most one container of the storage account, which needs a valid shared key
+func (m *Manager) Check(ctx context.Context) error {
+	if _, err := m.blobRequest(ctx, http.MethodGet, "/", url.Values{"comp": {"list"}, "maxresults": {"1"}}, nil); err != nil {
+		return fmt.Errorf("Azure List Containers API call failed: %w", err)
+	}
+	return nil
+}
+
+// containerRequest sends a blob service request about a container, signed with the shared key
+func (m *Manager) containerRequest(ctx context.Context, method, container string, query url.Values, header http.Header) (http.Header, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("restype", "container")
+	return m.blobRequest(ctx, method, "/"+url.PathEscape(container), query, header)
+}
+
+// blobRequest sends a blob service request, signed with the shared key
+func (m *Manager) blobRequest(ctx context.Context, method, path string, query url.Values, header http.Header) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.endpoint+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	signSharedKey(req, m.account, m.key, time.Now())
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Code: resp.Header.Get("x-ms-error-code"), Message: resp.Status}
+		var response struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if body, err := io.ReadAll(resp.Body); err == nil && xml.Unmarshal(body, &response) == nil && response.Message != "" {
+			apiErr.Code, apiErr.Message = response.Code, response.Message
+		}
+		return nil, apiErr
+	}
+	return resp.Header, nil
+}
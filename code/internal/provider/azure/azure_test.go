@@ -0,0 +1,433 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+const (
+	// azuriteAccount and azuriteKey are the well-known development credentials of Azurite
+	azuriteAccount = "devstoreaccount1"
+	azuriteKey     = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// fakeAzurite is an in-memory stand-in for the container endpoints of the blob service
+type fakeAzurite struct {
+	mu         sync.Mutex
+	containers map[string]map[string]string
+}
+
+func (f *fakeAzurite) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key, _ := base64.StdEncoding.DecodeString(azuriteKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign(req, azuriteAccount)))
+	if req.Header.Get("Authorization") != "SharedKey "+azuriteAccount+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		w.Header().Set("x-ms-error-code", "AuthorizationFailure")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	name := strings.TrimPrefix(req.URL.Path, "/"+azuriteAccount+"/")
+	metadata := map[string]string{}
+	for header, values := range req.Header {
+		if lower := strings.ToLower(header); strings.HasPrefix(lower, metadataHeaderPrefix) {
+			metadata[strings.TrimPrefix(lower, metadataHeaderPrefix)] = values[0]
+		}
+	}
+	_, found := f.containers[name]
+
+	switch {
+	case req.Method == http.MethodGet && name == "" && req.URL.Query().Get("comp") == "list":
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Containers /></EnumerationResults>`))
+	case req.Method == http.MethodPut && req.URL.Query().Get("comp") == "metadata" && found:
+		f.containers[name] = metadata
+	case req.Method == http.MethodPut && !found:
+		f.containers[name] = metadata
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut:
+		w.Header().Set("x-ms-error-code", "ContainerAlreadyExists")
+		w.WriteHeader(http.StatusConflict)
+	case !found:
+		w.Header().Set("x-ms-error-code", "ContainerNotFound")
+		w.WriteHeader(http.StatusNotFound)
+		if req.Method != http.MethodHead {
+			_, _ = w.Write([]byte("<Error><Code>ContainerNotFound</Code><Message>The specified container does not exist.</Message></Error>"))
+		}
+	case req.Method == http.MethodDelete:
+		delete(f.containers, name)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// fakeResourceManager is an in-memory stand-in for the storage account endpoints of Azure
+// Resource Manager and for the token endpoint of Microsoft Entra ID
+type fakeResourceManager struct {
+	mu           sync.Mutex
+	versioning   bool
+	policies     map[string]*immutabilityPolicy
+	etags        int
+	lifecycle    []json.RawMessage
+	unauthorized int
+}
+
+func (f *fakeResourceManager) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasSuffix(req.URL.Path, "/oauth2/v2.0/token") {
+		Expect(req.ParseForm()).To(Succeed())
+		Expect(req.PostForm.Get("client_id")).To(Equal("client"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer token" {
+		f.unauthorized++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	Expect(req.URL.Query().Get("api-version")).To(Equal(managementAPIVersion))
+	path := strings.TrimPrefix(req.URL.Path, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/"+azuriteAccount)
+
+	switch {
+	case path == "/blobServices/default" && req.Method == http.MethodGet:
+		_, _ = fmt.Fprintf(w, `{"properties":{"isVersioningEnabled":%t}}`, f.versioning)
+	case path == "/managementPolicies/default" && req.Method == http.MethodGet:
+		if f.lifecycle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		Expect(json.NewEncoder(w).Encode(map[string]any{"properties": map[string]any{"policy": map[string]any{"rules": f.lifecycle}}})).To(Succeed())
+	case path == "/managementPolicies/default" && req.Method == http.MethodPut:
+		var body struct {
+			Properties struct {
+				Policy struct {
+					Rules []json.RawMessage `json:"rules"`
+				} `json:"policy"`
+			} `json:"properties"`
+		}
+		Expect(json.NewDecoder(req.Body).Decode(&body)).To(Succeed())
+		f.lifecycle = body.Properties.Policy.Rules
+	case path == "/managementPolicies/default" && req.Method == http.MethodDelete:
+		f.lifecycle = nil
+	case strings.Contains(path, "/immutabilityPolicies/default"):
+		f.serveImmutabilityPolicy(w, req, path)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeResourceManager) serveImmutabilityPolicy(w http.ResponseWriter, req *http.Request, path string) {
+	container := strings.Split(strings.TrimPrefix(path, "/blobServices/default/containers/"), "/")[0]
+	policy, found := f.policies[container]
+	if req.Method != http.MethodGet && found && req.Header.Get("If-Match") != policy.Etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	var body immutabilityPolicy
+	if req.Method == http.MethodPut || strings.HasSuffix(path, "/extend") {
+		Expect(json.NewDecoder(req.Body).Decode(&body)).To(Succeed())
+	}
+	switch {
+	case req.Method == http.MethodGet:
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	case req.Method == http.MethodDelete:
+		Expect(policy.Properties.State).NotTo(Equal(immutabilityPolicyLocked))
+		delete(f.policies, container)
+		return
+	case strings.HasSuffix(path, "/lock"):
+		policy.Properties.State = immutabilityPolicyLocked
+	case strings.HasSuffix(path, "/extend"):
+		Expect(body.Properties.ImmutabilityPeriodSinceCreationInDays).To(BeNumerically(">", policy.Properties.ImmutabilityPeriodSinceCreationInDays))
+		policy.Properties.ImmutabilityPeriodSinceCreationInDays = body.Properties.ImmutabilityPeriodSinceCreationInDays
+	default:
+		Expect(found && policy.Properties.State == immutabilityPolicyLocked).To(BeFalse())
+		policy = &body
+		policy.Properties.State = "Unlocked"
+		f.policies[container] = policy
+	}
+	f.etags++
+	policy.Etag = fmt.Sprintf("\"%d\"", f.etags)
+	Expect(json.NewEncoder(w).Encode(policy)).To(Succeed())
+}
+
+var _ = Describe("Azure container manager", func() {
+	var (
+		ctx      context.Context
+		azurite  *fakeAzurite
+		blobs    *httptest.Server
+		endpoint string
+		s3bkt    *s3v1alpha1.S3Bucket
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		azurite = &fakeAzurite{containers: map[string]map[string]string{}}
+		blobs = httptest.NewServer(azurite)
+		DeferCleanup(blobs.Close)
+		endpoint = blobs.URL + "/" + azuriteAccount
+
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec: s3v1alpha1.S3BucketSpec{
+				Name:   "data",
+				Region: "westeurope",
+				Tags:   map[string]string{"Team": "storage", "cost-center": "42"},
+			},
+		}
+	})
+
+	Context("When only the blob service is reachable, as with Azurite", func() {
+		var manager *Manager
+
+		BeforeEach(func() {
+			var err error
+			manager, err = New(Config{AccountName: azuriteAccount, AccountKey: azuriteKey, Endpoint: endpoint})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should create the container with its tags as metadata and publish the connection details", func() {
+			state, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azurite.containers).To(HaveKeyWithValue("data", map[string]string{"team": "storage", "cost_center": "42"}))
+
+			Expect(state.URL).To(Equal(endpoint + "/data"))
+			Expect(state.Location).To(Equal("westeurope"))
+			Expect(state.ConnectionDetails).To(HaveKeyWithValue(AccountURLKey, endpoint))
+			Expect(state.Secret).To(HaveKeyWithValue(containerKey, []byte("data")))
+			Expect(state.Secret).To(HaveKeyWithValue(containerURLKey, []byte(endpoint+"/data")))
+			for _, value := range state.Secret {
+				Expect(string(value)).NotTo(ContainSubstring(azuriteKey))
+			}
+		})
+
+		It("should issue shared access signatures scoped to the container", func() {
+			now := time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC)
+			credentials, expiry, err := manager.IssueCredentials(s3bkt, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expiry).To(Equal(now.Add(7 * 24 * time.Hour)))
+			Expect(credentials).To(HaveKeyWithValue(sasExpiryKey, []byte("2025-01-09T15:04:05Z")))
+
+			token, err := url.ParseQuery(string(credentials[sasTokenKey]))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.Get("sr")).To(Equal("c"))
+			Expect(token.Get("sp")).To(Equal("racwdl"))
+			Expect(token.Get("st")).To(Equal("2025-01-02T14:49:05Z"))
+			Expect(token.Get("se")).To(Equal("2025-01-09T15:04:05Z"))
+			Expect(token.Get("spr")).To(Equal("https,http"))
+			Expect(string(credentials[sasURLKey])).To(Equal(endpoint + "/data?" + string(credentials[sasTokenKey])))
+		})
+
+		It("should replace the metadata of an existing container", func() {
+			_, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+
+			s3bkt.Spec.Tags = map[string]string{"env": "dev"}
+			_, err = manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azurite.containers["data"]).To(Equal(map[string]string{"env": "dev"}))
+		})
+
+		It("should report the fields that need Azure Resource Manager", func() {
+			s3bkt.Spec.Versioning = true
+			s3bkt.Spec.Locked = true
+			s3bkt.Spec.RequesterPays = true
+			s3bkt.Spec.Lifecycle = []s3v1alpha1.LifecycleRule{{ID: "logs", ExpirationDays: 30}}
+
+			state, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Unsupported).To(Equal([]string{"lifecycle", "locked", "requesterPays", "versioning"}))
+		})
+
+		It("should delete the container and ignore containers that no longer exist", func() {
+			_, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+			Expect(azurite.containers).To(BeEmpty())
+			Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+		})
+
+		It("should reject requests signed with another key", func() {
+			other, err := New(Config{AccountName: azuriteAccount, AccountKey: base64.StdEncoding.EncodeToString([]byte("other")), Endpoint: endpoint})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = other.Ensure(ctx, s3bkt)
+			Expect(err).To(MatchError(ContainSubstring("403 AuthorizationFailure")))
+		})
+
+		It("should check the shared key by listing the containers", func() {
+			Expect(manager.Check(ctx)).To(Succeed())
+
+			other, err := New(Config{AccountName: azuriteAccount, AccountKey: base64.StdEncoding.EncodeToString([]byte("other")), Endpoint: endpoint})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(other.Check(ctx)).To(MatchError(ContainSubstring("403 AuthorizationFailure")))
+		})
+
+		It("should reject a shared key that is not base64", func() {
+			_, err := New(Config{AccountName: azuriteAccount, AccountKey: "not base64!"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When Azure Resource Manager is configured", func() {
+		var (
+			arm     *fakeResourceManager
+			manager *Manager
+		)
+
+		BeforeEach(func() {
+			arm = &fakeResourceManager{versioning: true, policies: map[string]*immutabilityPolicy{}}
+			server := httptest.NewServer(arm)
+			DeferCleanup(server.Close)
+
+			var err error
+			manager, err = New(Config{
+				AccountName: azuriteAccount,
+				AccountKey:  azuriteKey,
+				Endpoint:    endpoint,
+				Management: &ManagementConfig{
+					Endpoint:       server.URL,
+					AuthorityHost:  server.URL,
+					SubscriptionID: "sub",
+					ResourceGroup:  "rg",
+					TenantID:       "tenant",
+					ClientID:       "client",
+					ClientSecret:   "secret",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			s3bkt.Spec.Versioning = true
+			s3bkt.Spec.Lifecycle = []s3v1alpha1.LifecycleRule{{
+				ID:                              "logs",
+				Prefix:                          "logs/",
+				ExpirationDays:                  90,
+				NoncurrentVersionExpirationDays: 7,
+				Transitions:                     []s3v1alpha1.LifecycleTransition{{Days: 30, StorageClass: "STANDARD_IA"}},
+			}}
+		})
+
+		It("should write the lifecycle rules of the container", func() {
+			state, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Unsupported).To(BeEmpty())
+			Expect(arm.unauthorized).To(BeZero())
+
+			Expect(arm.lifecycle).To(HaveLen(1))
+			Expect(arm.lifecycle[0]).To(MatchJSON(`{
+				"enabled": true,
+				"name": "datalogs",
+				"type": "Lifecycle",
+				"definition": {
+					"filters": {"blobTypes": ["blockBlob"], "prefixMatch": ["data/logs/"]},
+					"actions": {
+						"baseBlob": {
+							"delete": {"daysAfterCreationGreaterThan": 90},
+							"tierToCool": {"daysAfterCreationGreaterThan": 30}
+						},
+						"version": {"delete": {"daysAfterCreationGreaterThan": 7}}
+					}
+				}
+			}`))
+		})
+
+		It("should keep the lifecycle rules of other containers", func() {
+			other := json.RawMessage(`{"enabled":true,"name":"archive","type":"Lifecycle","definition":{"filters":{"blobTypes":["blockBlob"],"prefixMatch":["archive/"]},"actions":{"baseBlob":{"tierToArchive":{"daysAfterModificationGreaterThan":1}}}}}`)
+			arm.lifecycle = []json.RawMessage{other}
+
+			_, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.lifecycle).To(HaveLen(2))
+
+			Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+			Expect(arm.lifecycle).To(HaveLen(1))
+			Expect(arm.lifecycle[0]).To(MatchJSON(other))
+		})
+
+		It("should lock the immutability policy of locked buckets and only extend it", func() {
+			s3bkt.Spec.Locked = true
+			s3bkt.Spec.RetentionDays = 7
+			_, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.policies["data"].Properties.ImmutabilityPeriodSinceCreationInDays).To(Equal(int32(7)))
+			Expect(arm.policies["data"].Properties.State).To(Equal(immutabilityPolicyLocked))
+
+			s3bkt.Spec.RetentionDays = 14
+			_, err = manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.policies["data"].Properties.ImmutabilityPeriodSinceCreationInDays).To(Equal(int32(14)))
+
+			// Shortening a locked policy is not possible and left alone
+			s3bkt.Spec.RetentionDays = 1
+			_, err = manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.policies["data"].Properties.ImmutabilityPeriodSinceCreationInDays).To(Equal(int32(14)))
+		})
+
+		It("should remove an unlocked immutability policy no longer declared", func() {
+			s3bkt.Spec.RetentionDays = 7
+			_, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.policies).To(HaveKey("data"))
+
+			s3bkt.Spec.RetentionDays = 0
+			_, err = manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arm.policies).NotTo(HaveKey("data"))
+		})
+
+		It("should report locked buckets without a retention period", func() {
+			s3bkt.Spec.Locked = true
+			state, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Unsupported).To(Equal([]string{"locked"}))
+		})
+
+		It("should report versioning without enabling it on the storage account", func() {
+			arm.versioning = false
+			state, err := manager.Ensure(ctx, s3bkt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Unsupported).To(Equal([]string{"versioning"}))
+			Expect(arm.versioning).To(BeFalse())
+		})
+	})
+})
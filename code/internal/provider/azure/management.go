@@ -0,0 +1,380 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+const (
+	// managementAPIVersion is the Microsoft.Storage resource provider API version
+	managementAPIVersion = "2023-01-01"
+	// DefaultManagementEndpoint is the Azure Resource Manager endpoint of the public cloud
+	DefaultManagementEndpoint = "https://management.azure.com"
+	// DefaultAuthorityHost is the Microsoft Entra ID endpoint of the public cloud
+	DefaultAuthorityHost = "https://login.microsoftonline.com"
+	// immutabilityPolicyLocked is the state of a locked immutability policy
+	immutabilityPolicyLocked = "Locked"
+)
+
+// tiers maps S3 storage classes to the Azure lifecycle action moving blobs to the closest access tier
+var tiers = map[string]string{
+	"STANDARD_IA":  "tierToCool",
+	"ONEZONE_IA":   "tierToCool",
+	"GLACIER_IR":   "tierToCold",
+	"GLACIER":      "tierToArchive",
+	"DEEP_ARCHIVE": "tierToArchive",
+}
+
+// ManagementConfig locates the storage account in Azure Resource Manager and holds the
+// service principal used to manage it
+type ManagementConfig struct {
+	Endpoint       string
+	AuthorityHost  string
+	SubscriptionID string
+	ResourceGroup  string
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+}
+
+// management manages the storage account settings only available through Azure Resource Manager
+type management struct {
+	// account is the resource URL of the storage account
+	account string
+	client  *http.Client
+
+	// lifecycleMu serializes the updates of the management policy shared by every container
+	lifecycleMu sync.Mutex
+}
+
+func newManagement(config ManagementConfig, account string, base *http.Client) *management {
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = DefaultManagementEndpoint
+	}
+	authority := strings.TrimSuffix(config.AuthorityHost, "/")
+	if authority == "" {
+		authority = DefaultAuthorityHost
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", authority, config.TenantID),
+		Scopes:       []string{endpoint + "/.default"},
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	// Tokens refresh long after the reconcile that built the manager, so they use their own context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, base)
+
+	return &management{
+		account: fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s",
+			endpoint, config.SubscriptionID, config.ResourceGroup, account),
+		client: credentials.Client(ctx),
+	}
+}
+
+// versioningEnabled reports whether blob versioning is enabled on the storage account.
+// Versioning applies to every container of the account, so it is left to the account owner
+func (m *management) versioningEnabled(ctx context.Context) (bool, error) {
+	var service struct {
+		Properties struct {
+			IsVersioningEnabled bool `json:"isVersioningEnabled"`
+		} `json:"properties"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/blobServices/default", nil, nil, &service); err != nil {
+		return false, fmt.Errorf("Azure Get Blob Service Properties API call failed: %w", err)
+	}
+	return service.Properties.IsVersioningEnabled, nil
+}
+
+// immutabilityPolicy is the time-based retention policy of a container
+type immutabilityPolicy struct {
+	Etag       string `json:"etag"`
+	Properties struct {
+		ImmutabilityPeriodSinceCreationInDays int32  `json:"immutabilityPeriodSinceCreationInDays"`
+		State                                 string `json:"state"`
+	} `json:"properties"`
+}
+
+// applyImmutabilityPolicy sets the retention period of the container, locking it for locked
+// buckets. A locked policy can only be extended
+func (m *management) applyImmutabilityPolicy(ctx context.Context, container string, spec s3v1alpha1.S3BucketSpec) error {
+	log := logf.FromContext(ctx)
+	path := "/blobServices/default/containers/" + url.PathEscape(container) + "/immutabilityPolicies/default"
+
+	current := &immutabilityPolicy{}
+	if err := m.do(ctx, http.MethodGet, path, nil, nil, current); err != nil && !isNotFound(err) {
+		return fmt.Errorf("Azure Get Immutability Policy API call failed: %w", err)
+	}
+	days := spec.RetentionDays
+	observed := current.Properties.ImmutabilityPeriodSinceCreationInDays
+	locked := current.Properties.State == immutabilityPolicyLocked
+	body := map[string]any{"properties": map[string]any{"immutabilityPeriodSinceCreationInDays": days}}
+
+	switch {
+	case locked:
+		if days > observed {
+			log.Info("Extending locked immutability policy", "BucketName", container, "RetentionDays", days)
+			if err := m.do(ctx, http.MethodPost, path+"/extend", ifMatch(current.Etag), body, nil); err != nil {
+				return fmt.Errorf("Azure Extend Immutability Policy API call failed: %w", err)
+			}
+		}
+		return nil
+	case days == 0:
+		if observed > 0 {
+			log.Info("Deleting immutability policy", "BucketName", container)
+			if err := m.do(ctx, http.MethodDelete, path, ifMatch(current.Etag), nil, nil); err != nil {
+				return fmt.Errorf("Azure Delete Immutability Policy API call failed: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if days != observed {
+		log.Info("Setting immutability policy", "BucketName", container, "RetentionDays", days)
+		var header http.Header
+		if observed > 0 {
+			header = ifMatch(current.Etag)
+		}
+		if err := m.do(ctx, http.MethodPut, path, header, body, current); err != nil {
+			return fmt.Errorf("Azure Create Or Update Immutability Policy API call failed: %w", err)
+		}
+	}
+	if spec.Locked {
+		log.Info("Locking immutability policy", "BucketName", container)
+		if err := m.do(ctx, http.MethodPost, path+"/lock", ifMatch(current.Etag), nil, nil); err != nil {
+			return fmt.Errorf("Azure Lock Immutability Policy API call failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// policyRule is a rule of the storage account management policy
+type policyRule struct {
+	Enabled    bool             `json:"enabled"`
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Definition policyDefinition `json:"definition"`
+}
+
+type policyDefinition struct {
+	Filters policyFilters `json:"filters"`
+	Actions policyActions `json:"actions"`
+}
+
+type policyFilters struct {
+	BlobTypes   []string `json:"blobTypes"`
+	PrefixMatch []string `json:"prefixMatch"`
+}
+
+type policyActions struct {
+	BaseBlob map[string]policyCondition `json:"baseBlob,omitempty"`
+	Version  map[string]policyCondition `json:"version,omitempty"`
+}
+
+type policyCondition struct {
+	DaysAfterCreationGreaterThan int32 `json:"daysAfterCreationGreaterThan"`
+}
+
+// applyLifecycle replaces the management policy rules of the container with the lifecycle rules.
+// Rules of other containers are kept as they are. mapped is false when transitions to storage
+// classes Azure has no tier for were skipped
+func (m *management) applyLifecycle(ctx context.Context, container string, rules []s3v1alpha1.LifecycleRule) (mapped bool, err error) {
+	m.lifecycleMu.Lock()
+	defer m.lifecycleMu.Unlock()
+
+	var current struct {
+		Properties struct {
+			Policy struct {
+				Rules []json.RawMessage `json:"rules"`
+			} `json:"policy"`
+		} `json:"properties"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/managementPolicies/default", nil, nil, &current); err != nil && !isNotFound(err) {
+		return false, fmt.Errorf("Azure Get Management Policy API call failed: %w", err)
+	}
+
+	var updated []any
+	for _, raw := range current.Properties.Policy.Rules {
+		var rule policyRule
+		if json.Unmarshal(raw, &rule) == nil && ownedBy(rule, container) {
+			continue
+		}
+		updated = append(updated, raw)
+	}
+	owned, mapped := policyRules(container, rules)
+	for _, rule := range owned {
+		updated = append(updated, rule)
+	}
+	if len(updated) == len(current.Properties.Policy.Rules) && len(owned) == 0 {
+		// No rule of the container to add or remove
+		return mapped, nil
+	}
+
+	if len(updated) == 0 {
+		if err := m.do(ctx, http.MethodDelete, "/managementPolicies/default", nil, nil, nil); err != nil && !isNotFound(err) {
+			return false, fmt.Errorf("Azure Delete Management Policy API call failed: %w", err)
+		}
+		return mapped, nil
+	}
+
+	logf.FromContext(ctx).Info("Applying lifecycle management policy", "BucketName", container, "Rules", len(owned))
+	body := map[string]any{"properties": map[string]any{"policy": map[string]any{"rules": updated}}}
+	if err := m.do(ctx, http.MethodPut, "/managementPolicies/default", nil, body, nil); err != nil {
+		return false, fmt.Errorf("Azure Create Or Update Management Policy API call failed: %w", err)
+	}
+	return mapped, nil
+}
+
+// removeLifecycle removes the management policy rules of the container
+func (m *management) removeLifecycle(ctx context.Context, container string) error {
+	_, err := m.applyLifecycle(ctx, container, nil)
+	return err
+}
+
+// ownedBy reports whether every prefix of the rule is inside the container
+func ownedBy(rule policyRule, container string) bool {
+	if len(rule.Definition.Filters.PrefixMatch) == 0 {
+		return false
+	}
+	for _, prefix := range rule.Definition.Filters.PrefixMatch {
+		if !strings.HasPrefix(prefix, container+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// policyRules converts the lifecycle rules of a container to management policy rules
+func policyRules(container string, rules []s3v1alpha1.LifecycleRule) (policy []policyRule, mapped bool) {
+	mapped = true
+	for _, rule := range rules {
+		actions := policyActions{BaseBlob: map[string]policyCondition{}, Version: map[string]policyCondition{}}
+		if rule.ExpirationDays > 0 {
+			actions.BaseBlob["delete"] = policyCondition{DaysAfterCreationGreaterThan: rule.ExpirationDays}
+		}
+		if rule.NoncurrentVersionExpirationDays > 0 {
+			actions.Version["delete"] = policyCondition{DaysAfterCreationGreaterThan: rule.NoncurrentVersionExpirationDays}
+		}
+		for _, transition := range rule.Transitions {
+			tier, ok := tiers[transition.StorageClass]
+			if !ok {
+				mapped = false
+				continue
+			}
+			actions.BaseBlob[tier] = policyCondition{DaysAfterCreationGreaterThan: transition.Days}
+		}
+		if len(actions.BaseBlob) == 0 && len(actions.Version) == 0 {
+			continue
+		}
+
+		policy = append(policy, policyRule{
+			Enabled: true,
+			Name:    ruleName(container, rule.ID),
+			Type:    "Lifecycle",
+			Definition: policyDefinition{
+				Filters: policyFilters{
+					BlobTypes:   []string{"blockBlob"},
+					PrefixMatch: []string{container + "/" + rule.Prefix},
+				},
+				Actions: actions,
+			},
+		})
+	}
+	return policy, mapped
+}
+
+// ruleName returns the name of a management policy rule, which must be alphanumeric
+func ruleName(container, id string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, container+"-"+id)
+}
+
+// ifMatch returns the header making a request conditional on the etag of the resource
+func ifMatch(etag string) http.Header {
+	return http.Header{"If-Match": {etag}}
+}
+
+// do sends an Azure Resource Manager request about the storage account and decodes the
+// response into out when it is not nil
+func (m *management) do(ctx context.Context, method, path string, header http.Header, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	target := m.account + path + "?api-version=" + managementAPIVersion
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+		var response struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&response) == nil && response.Error.Message != "" {
+			apiErr.Code, apiErr.Message = response.Error.Code, response.Error.Message
+		}
+		return apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shared key authorization", func() {
+	It("should canonicalize path-style requests like Azurite expects", func() {
+		req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:10000/devstoreaccount1/data?restype=container&comp=metadata", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("x-ms-meta-team", "storage")
+		signSharedKey(req, "devstoreaccount1", []byte("key"), time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC))
+
+		Expect(stringToSign(req, "devstoreaccount1")).To(Equal("PUT\n\n\n\n\n\n\n\n\n\n\n\n" +
+			"x-ms-date:Thu, 02 Jan 2025 15:04:05 GMT\nx-ms-meta-team:storage\nx-ms-version:2021-12-02\n" +
+			"/devstoreaccount1/devstoreaccount1/data\ncomp:metadata\nrestype:container"))
+		Expect(req.Header.Get("Authorization")).To(HavePrefix("SharedKey devstoreaccount1:"))
+	})
+
+	It("should sign container shared access signatures", func() {
+		start := time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC)
+		token, err := url.ParseQuery(containerSAS("devstoreaccount1", []byte("key"), "data", start, start.Add(time.Hour), "https"))
+		Expect(err).NotTo(HaveOccurred())
+
+		mac := hmac.New(sha256.New, []byte("key"))
+		mac.Write([]byte("racwdl\n2025-01-02T15:04:05Z\n2025-01-02T16:04:05Z\n/blob/devstoreaccount1/data\n\n\n" +
+			"https\n2021-12-02\nc\n\n\n\n\n\n\n"))
+		Expect(token.Get("sig")).To(Equal(base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+		Expect(token.Get("sv")).To(Equal("2021-12-02"))
+	})
+})
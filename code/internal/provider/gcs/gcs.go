@@ -0,0 +1,463 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gcs manages Google Cloud Storage buckets through the JSON API.
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+const (
+	// DefaultEndpoint is the Google Cloud Storage API endpoint
+	DefaultEndpoint = "https://storage.googleapis.com"
+	// scope grants full control of the buckets of the project
+	scope = "https://www.googleapis.com/auth/devstorage.full_control"
+	// defaultLocation is the location of buckets that set no region
+	defaultLocation = "US"
+	// secondsPerDay converts retention days to the retention period in seconds
+	secondsPerDay = 24 * 60 * 60
+	// maxLabelLength is the maximum length of label keys and values
+	maxLabelLength = 63
+)
+
+// storageClasses maps S3 storage classes to the GCS class with the closest minimum storage duration
+var storageClasses = map[string]string{
+	"STANDARD":     "STANDARD",
+	"STANDARD_IA":  "NEARLINE",
+	"ONEZONE_IA":   "NEARLINE",
+	"GLACIER_IR":   "COLDLINE",
+	"GLACIER":      "COLDLINE",
+	"DEEP_ARCHIVE": "ARCHIVE",
+}
+
+// supportedFields are the spec fields mapped to GCS bucket settings
+var supportedFields = []string{
+	"locked", "retentionDays", "versioning", "tags", "lifecycle", "website", "requesterPays", "defaultStorageClass",
+}
+
+// Config describes how to reach Google Cloud Storage
+type Config struct {
+	// Endpoint overrides DefaultEndpoint, e.g. for a local fake GCS server
+	Endpoint string
+	// Project owns the buckets created
+	Project string
+	// CredentialsJSON is a service account key. Without it, application default credentials are
+	// used against DefaultEndpoint and no credentials at all against a custom endpoint
+	CredentialsJSON []byte
+	// HTTPClient is the base client of the requests, e.g. trusting a custom CA bundle
+	HTTPClient *http.Client
+	// FineGrainedAccess keeps object ACLs instead of enabling uniform bucket-level access
+	FineGrainedAccess bool
+}
+
+// Manager manages the GCS buckets of one project
+type Manager struct {
+	endpoint          string
+	project           string
+	client            *http.Client
+	fineGrainedAccess bool
+}
+
+var (
+	_ provider.BucketManager = &Manager{}
+	_ provider.Checker       = &Manager{}
+)
+
+// New returns a Manager for config
+func New(config Config) (*Manager, error) {
+	base := config.HTTPClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+	// Tokens refresh long after the reconcile that built the manager, so they use their own context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, base)
+
+	m := &Manager{
+		endpoint:          strings.TrimSuffix(config.Endpoint, "/"),
+		project:           config.Project,
+		client:            base,
+		fineGrainedAccess: config.FineGrainedAccess,
+	}
+	switch {
+	case len(config.CredentialsJSON) > 0:
+		credentials, err := google.CredentialsFromJSON(ctx, config.CredentialsJSON, scope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GCS credentials: %w", err)
+		}
+		m.client = oauth2.NewClient(ctx, credentials.TokenSource)
+	case m.endpoint == "":
+		// Application default credentials, e.g. GKE workload identity
+		credentials, err := google.FindDefaultCredentials(ctx, scope)
+		if err != nil {
+			return nil, fmt.Errorf("no GCS credentials found: %w", err)
+		}
+		m.client = oauth2.NewClient(ctx, credentials.TokenSource)
+	}
+	if m.endpoint == "" {
+		m.endpoint = DefaultEndpoint
+	}
+	return m, nil
+}
+
+// bucket is the subset of the GCS bucket resource the manager reads
+type bucket struct {
+	Name            string            `json:"name"`
+	Location        string            `json:"location"`
+	Metageneration  string            `json:"metageneration,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	RetentionPolicy *retentionPolicy  `json:"retentionPolicy,omitempty"`
+}
+
+type retentionPolicy struct {
+	RetentionPeriod string `json:"retentionPeriod"`
+	IsLocked        bool   `json:"isLocked,omitempty"`
+}
+
+type lifecycleRule struct {
+	Action    lifecycleAction    `json:"action"`
+	Condition lifecycleCondition `json:"condition"`
+}
+
+type lifecycleAction struct {
+	Type         string `json:"type"`
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+type lifecycleCondition struct {
+	Age                     *int32   `json:"age,omitempty"`
+	DaysSinceNoncurrentTime int32    `json:"daysSinceNoncurrentTime,omitempty"`
+	MatchesPrefix           []string `json:"matchesPrefix,omitempty"`
+}
+
+// APIError is an error response of the GCS JSON API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GCS API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// isNotFound reports whether err is a GCS 404 response
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Ensure creates the bucket when it does not exist and applies the spec to it
+func (m *Manager) Ensure(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*provider.BucketState, error) {
+	log := logf.FromContext(ctx)
+
+	var (
+		fields      map[string]any
+		unsupported []string
+	)
+	current := &bucket{}
+	err := m.do(ctx, http.MethodGet, bucketPath(s3bkt.Spec.Name), nil, nil, current)
+	switch {
+	case isNotFound(err):
+		log.Info("Creating GCS bucket", "BucketName", s3bkt.Spec.Name)
+		fields, unsupported = bucketFields(s3bkt.Spec, !m.fineGrainedAccess, nil)
+		fields["name"] = s3bkt.Spec.Name
+		fields["location"] = defaultLocation
+		if s3bkt.Spec.Region != "" {
+			fields["location"] = s3bkt.Spec.Region
+		}
+		fields["labels"] = labels(s3bkt.Spec.Tags)
+		for key, value := range fields {
+			// Settings being removed do not exist on a new bucket
+			if value == nil {
+				delete(fields, key)
+			}
+		}
+		current = &bucket{}
+		if err := m.do(ctx, http.MethodPost, "/storage/v1/b", url.Values{"project": {m.project}}, fields, current); err != nil {
+			return nil, fmt.Errorf("GCS buckets.insert API call failed: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("GCS buckets.get API call failed: %w", err)
+	default:
+		log.Info("Updating GCS bucket", "BucketName", s3bkt.Spec.Name)
+		fields, unsupported = bucketFields(s3bkt.Spec, !m.fineGrainedAccess, current)
+		if len(s3bkt.Spec.Tags) > 0 || s3bkt.Spec.DriftPolicy == s3v1alpha1.DriftPolicyEnforce {
+			fields["labels"] = labelsPatch(current.Labels, labels(s3bkt.Spec.Tags))
+		}
+		current = &bucket{}
+		if err := m.do(ctx, http.MethodPatch, bucketPath(s3bkt.Spec.Name), nil, fields, current); err != nil {
+			return nil, fmt.Errorf("GCS buckets.patch API call failed: %w", err)
+		}
+	}
+
+	// A locked retention policy can never be shortened or removed
+	if s3bkt.Spec.Locked && s3bkt.Spec.RetentionDays > 0 && (current.RetentionPolicy == nil || !current.RetentionPolicy.IsLocked) {
+		log.Info("Locking GCS retention policy", "BucketName", s3bkt.Spec.Name, "RetentionDays", s3bkt.Spec.RetentionDays)
+		query := url.Values{"ifMetagenerationMatch": {current.Metageneration}}
+		if err := m.do(ctx, http.MethodPost, bucketPath(s3bkt.Spec.Name)+"/lockRetentionPolicy", query, nil, current); err != nil {
+			return nil, fmt.Errorf("GCS buckets.lockRetentionPolicy API call failed: %w", err)
+		}
+	}
+
+	return &provider.BucketState{
+		Location:    current.Location,
+		URL:         m.endpoint + "/" + s3bkt.Spec.Name,
+		Unsupported: unsupported,
+	}, nil
+}
+
+// Check lists at most one bucket of the project, which needs a valid token
+func (m *Manager) Check(ctx context.Context) error {
+	query := url.Values{"project": {m.project}, "maxResults": {"1"}, "fields": {"kind"}}
+	if err := m.do(ctx, http.MethodGet, "/storage/v1/b", query, nil, nil); err != nil {
+		return fmt.Errorf("GCS buckets.list API call failed: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the bucket, which must be empty
+func (m *Manager) Delete(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+	log.Info("Deleting GCS bucket", "BucketName", s3bkt.Spec.Name)
+
+	err := m.do(ctx, http.MethodDelete, bucketPath(s3bkt.Spec.Name), nil, nil, nil)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("GCS buckets.delete API call failed: %w", err)
+	}
+	return nil
+}
+
+// bucketFields maps the spec to the GCS bucket settings it manages, with nil values for settings
+// to remove, and lists the requested spec fields GCS does not implement. Settings the spec does
+// not declare are only reset on existing buckets under the Enforce drift policy, and a locked
+// retention policy of the current bucket is never patched
+func bucketFields(spec s3v1alpha1.S3BucketSpec, uniformAccess bool, current *bucket) (map[string]any, []string) {
+	unsupported := provider.UnsupportedFields(spec, supportedFields...)
+	enforce := spec.DriftPolicy == s3v1alpha1.DriftPolicyEnforce
+
+	fields := map[string]any{}
+	if spec.Versioning || enforce {
+		fields["versioning"] = map[string]any{"enabled": spec.Versioning}
+	}
+	if spec.RequesterPays || enforce {
+		fields["billing"] = map[string]any{"requesterPays": spec.RequesterPays}
+	}
+	if current == nil || enforce {
+		fields["iamConfiguration"] = map[string]any{"uniformBucketLevelAccess": map[string]any{"enabled": uniformAccess}}
+	}
+
+	if spec.RetentionDays > 0 {
+		fields["retentionPolicy"] = map[string]any{
+			"retentionPeriod": strconv.FormatInt(int64(spec.RetentionDays)*secondsPerDay, 10),
+		}
+	} else {
+		if enforce {
+			fields["retentionPolicy"] = nil
+		}
+		if spec.Locked {
+			// GCS locks retention policies, not buckets
+			unsupported = append(unsupported, "locked")
+		}
+	}
+	if current != nil && current.RetentionPolicy != nil && current.RetentionPolicy.IsLocked {
+		// GCS rejects any change to a locked retention policy
+		delete(fields, "retentionPolicy")
+	}
+
+	if spec.Website != nil {
+		fields["website"] = map[string]any{
+			"mainPageSuffix": spec.Website.IndexDocument,
+			"notFoundPage":   spec.Website.ErrorDocument,
+		}
+		if len(spec.Website.RoutingRules) > 0 {
+			unsupported = append(unsupported, "website.routingRules")
+		}
+		if spec.Website.ExternalService {
+			unsupported = append(unsupported, "website.externalService")
+		}
+	} else if enforce {
+		fields["website"] = nil
+	}
+
+	if spec.DefaultStorageClass != "" {
+		if class, ok := storageClasses[spec.DefaultStorageClass]; ok {
+			fields["storageClass"] = class
+		} else {
+			unsupported = append(unsupported, "defaultStorageClass")
+		}
+	}
+
+	rules, mapped := lifecycleRules(spec.Lifecycle)
+	if !mapped {
+		unsupported = append(unsupported, "lifecycle.transitions")
+	}
+	if len(rules) > 0 || enforce {
+		fields["lifecycle"] = map[string]any{"rule": rules}
+	}
+
+	sort.Strings(unsupported)
+	return fields, unsupported
+}
+
+// lifecycleRules converts the lifecycle rules to GCS rules, skipping transitions to storage
+// classes GCS has no equivalent for, in which case mapped is false
+func lifecycleRules(rules []s3v1alpha1.LifecycleRule) (gcsRules []lifecycleRule, mapped bool) {
+	gcsRules, mapped = []lifecycleRule{}, true
+	for _, rule := range rules {
+		var prefixes []string
+		if rule.Prefix != "" {
+			prefixes = []string{rule.Prefix}
+		}
+		if rule.ExpirationDays > 0 {
+			gcsRules = append(gcsRules, lifecycleRule{
+				Action:    lifecycleAction{Type: "Delete"},
+				Condition: lifecycleCondition{Age: &rule.ExpirationDays, MatchesPrefix: prefixes},
+			})
+		}
+		if rule.NoncurrentVersionExpirationDays > 0 {
+			gcsRules = append(gcsRules, lifecycleRule{
+				Action:    lifecycleAction{Type: "Delete"},
+				Condition: lifecycleCondition{DaysSinceNoncurrentTime: rule.NoncurrentVersionExpirationDays, MatchesPrefix: prefixes},
+			})
+		}
+		for _, transition := range rule.Transitions {
+			class, ok := storageClasses[transition.StorageClass]
+			if !ok {
+				mapped = false
+				continue
+			}
+			gcsRules = append(gcsRules, lifecycleRule{
+				Action:    lifecycleAction{Type: "SetStorageClass", StorageClass: class},
+				Condition: lifecycleCondition{Age: &transition.Days, MatchesPrefix: prefixes},
+			})
+		}
+	}
+	return gcsRules, mapped
+}
+
+// labels converts tags to GCS labels. Keys and values may only hold lowercase letters, digits,
+// dashes and underscores, up to 63 characters, and keys must start with a letter
+func labels(tags map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range tags {
+		name := labelValue(key)
+		if name == "" || !unicode.IsLetter(rune(name[0])) {
+			name = labelValue("tag_" + name)
+		}
+		result[name] = labelValue(value)
+	}
+	return result
+}
+
+// labelValue lowercases value, replaces the characters GCS labels do not allow with
+// underscores and truncates it to the maximum label length
+func labelValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, value)
+	if len(value) > maxLabelLength {
+		value = value[:maxLabelLength]
+	}
+	return value
+}
+
+// labelsPatch returns the labels patch replacing current with desired. Patches merge labels,
+// so labels to remove are set to nil
+func labelsPatch(current, desired map[string]string) map[string]any {
+	patch := map[string]any{}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	for key, value := range desired {
+		patch[key] = value
+	}
+	return patch
+}
+
+// bucketPath returns the JSON API path of a bucket
+func bucketPath(name string) string {
+	return "/storage/v1/b/" + url.PathEscape(name)
+}
+
+// do sends a JSON API request and decodes the response into out when it is not nil
+func (m *Manager) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	target := m.endpoint + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+		var response struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&response) == nil && response.Error.Message != "" {
+			apiErr.Message = response.Error.Message
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
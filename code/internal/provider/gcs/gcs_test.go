@@ -0,0 +1,319 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// fakeGCS is an in-memory stand-in for the bucket endpoints of the GCS JSON API
+type fakeGCS struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]any
+	project string
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name, action, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/storage/v1/b"), "/lock")
+	name = strings.TrimPrefix(name, "/")
+	existing, found := f.buckets[name]
+
+	switch {
+	case name == "forbidden-bucket":
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"message":"Permission denied on the bucket."}}`))
+	case req.Method == http.MethodGet && name == "":
+		f.project = req.URL.Query().Get("project")
+		_, _ = w.Write([]byte(`{"kind":"storage#buckets"}`))
+	case req.Method == http.MethodPost && name == "":
+		fields := map[string]any{}
+		Expect(json.NewDecoder(req.Body).Decode(&fields)).To(Succeed())
+		f.project = req.URL.Query().Get("project")
+		fields["metageneration"] = "1"
+		f.buckets[fields["name"].(string)] = fields
+		f.respond(w, fields)
+	case !found:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The specified bucket does not exist."}}`))
+	case req.Method == http.MethodGet:
+		f.respond(w, existing)
+	case req.Method == http.MethodPatch:
+		patch := map[string]any{}
+		Expect(json.NewDecoder(req.Body).Decode(&patch)).To(Succeed())
+		if _, changed := patch["retentionPolicy"]; changed && existing["retentionPolicy"] != nil &&
+			existing["retentionPolicy"].(map[string]any)["isLocked"] == true {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"Cannot change a locked retention policy."}}`))
+			return
+		}
+		for key, value := range patch {
+			if key == "labels" && existing["labels"] != nil {
+				merged := existing["labels"].(map[string]any)
+				for label, labelValue := range value.(map[string]any) {
+					merged[label] = labelValue
+					if labelValue == nil {
+						delete(merged, label)
+					}
+				}
+				continue
+			}
+			existing[key] = value
+			if value == nil {
+				delete(existing, key)
+			}
+		}
+		f.bumpMetageneration(existing)
+		f.respond(w, existing)
+	case req.Method == http.MethodPost && action == "RetentionPolicy":
+		if req.URL.Query().Get("ifMetagenerationMatch") != existing["metageneration"] {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		existing["retentionPolicy"].(map[string]any)["isLocked"] = true
+		f.bumpMetageneration(existing)
+		f.respond(w, existing)
+	case req.Method == http.MethodDelete:
+		delete(f.buckets, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeGCS) bumpMetageneration(bucket map[string]any) {
+	generation, _ := strconv.Atoi(bucket["metageneration"].(string))
+	bucket["metageneration"] = strconv.Itoa(generation + 1)
+}
+
+func (f *fakeGCS) respond(w http.ResponseWriter, bucket map[string]any) {
+	response := map[string]any{"location": "US"}
+	for key, value := range bucket {
+		response[key] = value
+	}
+	if location, ok := response["location"].(string); ok {
+		response["location"] = strings.ToUpper(location)
+	}
+	Expect(json.NewEncoder(w).Encode(response)).To(Succeed())
+}
+
+var _ = Describe("GCS bucket manager", func() {
+	var (
+		ctx     context.Context
+		fake    *fakeGCS
+		server  *httptest.Server
+		manager *Manager
+		s3bkt   *s3v1alpha1.S3Bucket
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = &fakeGCS{buckets: map[string]map[string]any{}}
+		server = httptest.NewServer(fake)
+		DeferCleanup(server.Close)
+
+		var err error
+		manager, err = New(Config{Endpoint: server.URL, Project: "my-project"})
+		Expect(err).NotTo(HaveOccurred())
+
+		s3bkt = &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec: s3v1alpha1.S3BucketSpec{
+				Name:       "my-gcs-bucket",
+				Region:     "europe-west1",
+				Versioning: true,
+				Tags:       map[string]string{"Team": "Storage"},
+				Lifecycle: []s3v1alpha1.LifecycleRule{{
+					ID:             "logs",
+					Prefix:         "logs/",
+					ExpirationDays: 30,
+					Transitions:    []s3v1alpha1.LifecycleTransition{{Days: 7, StorageClass: "STANDARD_IA"}},
+				}},
+			},
+		}
+	})
+
+	It("creates the bucket with the settings mapped from the spec", func() {
+		state, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Location).To(Equal("EUROPE-WEST1"))
+		Expect(state.URL).To(Equal(server.URL + "/my-gcs-bucket"))
+		Expect(state.Unsupported).To(BeEmpty())
+		Expect(fake.project).To(Equal("my-project"))
+
+		created := fake.buckets["my-gcs-bucket"]
+		Expect(created["versioning"]).To(HaveKeyWithValue("enabled", true))
+		Expect(created["labels"]).To(Equal(map[string]any{"team": "storage"}))
+		Expect(created["iamConfiguration"]).To(HaveKeyWithValue("uniformBucketLevelAccess", HaveKeyWithValue("enabled", true)))
+		Expect(created).NotTo(HaveKey("retentionPolicy"))
+		Expect(created["lifecycle"]).To(HaveKeyWithValue("rule", ConsistOf(
+			map[string]any{
+				"action":    map[string]any{"type": "Delete"},
+				"condition": map[string]any{"age": float64(30), "matchesPrefix": []any{"logs/"}},
+			},
+			map[string]any{
+				"action":    map[string]any{"type": "SetStorageClass", "storageClass": "NEARLINE"},
+				"condition": map[string]any{"age": float64(7), "matchesPrefix": []any{"logs/"}},
+			},
+		)))
+	})
+
+	It("keeps the settings of an existing bucket the spec does not declare", func() {
+		fake.buckets["my-gcs-bucket"] = map[string]any{
+			"name":             "my-gcs-bucket",
+			"metageneration":   "1",
+			"versioning":       map[string]any{"enabled": true},
+			"billing":          map[string]any{"requesterPays": true},
+			"iamConfiguration": map[string]any{"uniformBucketLevelAccess": map[string]any{"enabled": false}},
+			"retentionPolicy":  map[string]any{"retentionPeriod": "86400"},
+			"labels":           map[string]any{"owner": "finance"},
+		}
+		s3bkt.Spec = s3v1alpha1.S3BucketSpec{Name: "my-gcs-bucket"}
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+
+		adopted := fake.buckets["my-gcs-bucket"]
+		Expect(adopted["versioning"]).To(HaveKeyWithValue("enabled", true))
+		Expect(adopted["billing"]).To(HaveKeyWithValue("requesterPays", true))
+		Expect(adopted["iamConfiguration"]).To(
+			HaveKeyWithValue("uniformBucketLevelAccess", HaveKeyWithValue("enabled", false)))
+		Expect(adopted["retentionPolicy"]).To(HaveKeyWithValue("retentionPeriod", "86400"))
+		Expect(adopted["labels"]).To(Equal(map[string]any{"owner": "finance"}))
+	})
+
+	It("updates an existing bucket and removes settings no longer declared under the Enforce drift policy", func() {
+		s3bkt.Spec.DriftPolicy = s3v1alpha1.DriftPolicyEnforce
+		s3bkt.Spec.RetentionDays = 7
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.buckets["my-gcs-bucket"]["retentionPolicy"]).To(HaveKeyWithValue("retentionPeriod", "604800"))
+
+		s3bkt.Spec.RetentionDays = 0
+		s3bkt.Spec.Versioning = false
+		s3bkt.Spec.Tags = map[string]string{"env": "dev"}
+		s3bkt.Spec.Lifecycle = nil
+		_, err = manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := fake.buckets["my-gcs-bucket"]
+		Expect(updated).NotTo(HaveKey("retentionPolicy"))
+		Expect(updated["versioning"]).To(HaveKeyWithValue("enabled", false))
+		Expect(updated["labels"]).To(Equal(map[string]any{"env": "dev"}))
+		Expect(updated["lifecycle"]).To(HaveKeyWithValue("rule", BeEmpty()))
+	})
+
+	It("converts tags into valid labels", func() {
+		s3bkt.Spec.Tags = map[string]string{
+			"app.kubernetes.io/name": "Web App",
+			"2fa":                    "on",
+			"Team":                   strings.Repeat("x", 70),
+		}
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.buckets["my-gcs-bucket"]["labels"]).To(Equal(map[string]any{
+			"app_kubernetes_io_name": "web_app",
+			"tag_2fa":                "on",
+			"team":                   strings.Repeat("x", 63),
+		}))
+	})
+
+	It("keeps object ACLs with fine-grained access", func() {
+		fineGrained, err := New(Config{Endpoint: server.URL, Project: "my-project", FineGrainedAccess: true})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fineGrained.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.buckets["my-gcs-bucket"]["iamConfiguration"]).To(
+			HaveKeyWithValue("uniformBucketLevelAccess", HaveKeyWithValue("enabled", false)))
+	})
+
+	It("locks the retention policy of locked buckets", func() {
+		s3bkt.Spec.Locked = true
+		s3bkt.Spec.RetentionDays = 1
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.buckets["my-gcs-bucket"]["retentionPolicy"]).To(HaveKeyWithValue("isLocked", true))
+
+		// Ensuring again leaves the locked policy alone, even when enforcing its removal
+		_, err = manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		s3bkt.Spec.DriftPolicy = s3v1alpha1.DriftPolicyEnforce
+		s3bkt.Spec.RetentionDays = 0
+		_, err = manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.buckets["my-gcs-bucket"]["retentionPolicy"]).To(HaveKeyWithValue("retentionPeriod", "86400"))
+	})
+
+	It("reports the requested fields GCS does not implement", func() {
+		s3bkt.Spec.Locked = true
+		s3bkt.Spec.Acceleration = true
+		s3bkt.Spec.Inventory = []s3v1alpha1.InventoryConfiguration{{ID: "daily"}}
+		s3bkt.Spec.DefaultStorageClass = "INTELLIGENT_TIERING"
+		s3bkt.Spec.Website = &s3v1alpha1.WebsiteSpec{
+			IndexDocument: "index.html",
+			RoutingRules:  []s3v1alpha1.RoutingRule{{Redirect: s3v1alpha1.RoutingRuleRedirect{HostName: "example.com"}}},
+		}
+		s3bkt.Spec.Lifecycle[0].Transitions = append(s3bkt.Spec.Lifecycle[0].Transitions,
+			s3v1alpha1.LifecycleTransition{Days: 30, StorageClass: "INTELLIGENT_TIERING"})
+
+		state, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Unsupported).To(Equal([]string{
+			"acceleration", "defaultStorageClass", "inventory", "lifecycle.transitions", "locked", "website.routingRules",
+		}))
+		Expect(fake.buckets["my-gcs-bucket"]["website"]).To(HaveKeyWithValue("mainPageSuffix", "index.html"))
+	})
+
+	It("deletes the bucket and ignores buckets that no longer exist", func() {
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+		Expect(fake.buckets).NotTo(HaveKey("my-gcs-bucket"))
+		Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+	})
+
+	It("returns the error message of failed API calls", func() {
+		s3bkt.Spec.Name = "forbidden-bucket"
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).To(MatchError("GCS buckets.get API call failed: GCS API returned 403: Permission denied on the bucket."))
+	})
+
+	It("checks its connectivity by listing the buckets of the project", func() {
+		Expect(manager.Check(ctx)).To(Succeed())
+		Expect(fake.project).To(Equal("my-project"))
+
+		server.Close()
+		Expect(manager.Check(ctx)).To(MatchError(ContainSubstring("GCS buckets.list API call failed")))
+	})
+
+	It("rejects invalid credentials", func() {
+		_, err := New(Config{Endpoint: server.URL, CredentialsJSON: []byte("not json")})
+		Expect(err).To(MatchError(ContainSubstring("invalid GCS credentials")))
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package local stores buckets as directories of the operator's filesystem and serves them
+// over a minimal S3-compatible endpoint, for development clusters without cloud access.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+const (
+	// defaultRegion is the region S3 clients sign their requests for
+	defaultRegion = "us-east-1"
+	// developmentCredential is published as access key; the Server does not verify signatures
+	developmentCredential = "local"
+	// EndpointKey is the ConfigMap key of the S3-compatible endpoint
+	EndpointKey = "Endpoint"
+)
+
+// Manager manages the buckets stored under one root directory
+type Manager struct {
+	root     string
+	endpoint string
+}
+
+var (
+	_ provider.BucketManager = &Manager{}
+	_ provider.UsageReporter = &Manager{}
+	_ provider.Checker       = &Manager{}
+)
+
+// New returns a Manager creating buckets under root. Applications reach them through the
+// S3-compatible endpoint, if any
+func New(root, endpoint string) *Manager {
+	return &Manager{root: filepath.Clean(root), endpoint: endpoint}
+}
+
+// Ensure creates the bucket directory when it does not exist
+func (m *Manager) Ensure(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*provider.BucketState, error) {
+	log := logf.FromContext(ctx)
+
+	dir, err := bucketDir(m.root, s3bkt.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Ensuring local bucket directory", "BucketName", s3bkt.Spec.Name, "Path", dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create bucket directory: %w", err)
+	}
+
+	region := s3bkt.Spec.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	state := &provider.BucketState{
+		Location: region,
+		URL:      "file://" + dir,
+		// Directories have none of the optional bucket features
+		Unsupported: provider.UnsupportedFields(s3bkt.Spec),
+	}
+	if m.endpoint != "" {
+		state.URL = m.endpoint + "/" + s3bkt.Spec.Name
+		state.ConnectionDetails = map[string]string{EndpointKey: m.endpoint}
+		state.Secret = map[string][]byte{
+			"BUCKET_NAME":           []byte(s3bkt.Spec.Name),
+			"AWS_REGION":            []byte(region),
+			"AWS_ENDPOINT_URL":      []byte(m.endpoint),
+			"AWS_ACCESS_KEY_ID":     []byte(developmentCredential),
+			"AWS_SECRET_ACCESS_KEY": []byte(developmentCredential),
+		}
+	}
+	return state, nil
+}
+
+// Delete removes the bucket directory, which must be empty like an S3 bucket
+func (m *Manager) Delete(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error {
+	log := logf.FromContext(ctx)
+
+	dir, err := bucketDir(m.root, s3bkt.Spec.Name)
+	if err != nil {
+		return err
+	}
+	log.Info("Deleting local bucket directory", "BucketName", s3bkt.Spec.Name, "Path", dir)
+	err = os.Remove(dir)
+	switch {
+	case err == nil, errors.Is(err, os.ErrNotExist):
+		return nil
+	case errors.Is(err, syscall.ENOTEMPTY), errors.Is(err, syscall.EEXIST):
+		return fmt.Errorf("bucket %s is not empty", s3bkt.Spec.Name)
+	default:
+		return fmt.Errorf("failed to delete bucket directory: %w", err)
+	}
+}
+
+// Check verifies that the root directory of the buckets exists
+func (m *Manager) Check(_ context.Context) error {
+	info, err := os.Stat(m.root)
+	if err != nil {
+		return fmt.Errorf("bucket root is not accessible: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("bucket root %s is not a directory", m.root)
+	}
+	return nil
+}
+
+// Usage walks the bucket directory and sums the size of its files. Uploads in progress are
+// not counted, like in listings
+func (m *Manager) Usage(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, maxObjects int64) (*s3v1alpha1.BucketUsage, error) {
+	dir, err := bucketDir(m.root, s3bkt.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	usage := &s3v1alpha1.BucketUsage{Source: s3v1alpha1.UsageSourceProvider}
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), uploadPrefix) {
+			return nil
+		}
+		if maxObjects > 0 && usage.ObjectCount >= maxObjects {
+			usage.Truncated = true
+			return filepath.SkipAll
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		usage.ObjectCount++
+		usage.SizeBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure bucket directory: %w", err)
+	}
+	return usage, nil
+}
+
+// bucketDir returns the directory of a bucket, rejecting names that are not a single path element
+func bucketDir(root, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return "", fmt.Errorf("invalid bucket name %q", name)
+	}
+	return filepath.Join(root, name), nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("Local Manager", func() {
+	var (
+		ctx  context.Context
+		root string
+	)
+
+	bucket := func(name string) *s3v1alpha1.S3Bucket {
+		return &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: name},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		root = GinkgoT().TempDir()
+	})
+
+	It("creates the bucket directory and reports its path", func() {
+		s3bkt := bucket("dev-bucket")
+		s3bkt.Spec.Versioning = true
+
+		state, err := New(root, "").Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(root, "dev-bucket")).To(BeADirectory())
+		Expect(state.Location).To(Equal("us-east-1"))
+		Expect(state.URL).To(Equal("file://" + filepath.Join(root, "dev-bucket")))
+		Expect(state.Unsupported).To(Equal([]string{"versioning"}))
+		Expect(state.Secret).To(BeNil())
+
+		By("being idempotent")
+		_, err = New(root, "").Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("publishes the S3-compatible endpoint when there is one", func() {
+		s3bkt := bucket("dev-bucket")
+		s3bkt.Spec.Region = "eu-west-1"
+
+		state, err := New(root, "http://local-s3:9000").Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.URL).To(Equal("http://local-s3:9000/dev-bucket"))
+		Expect(state.Location).To(Equal("eu-west-1"))
+		Expect(state.ConnectionDetails).To(HaveKeyWithValue(EndpointKey, "http://local-s3:9000"))
+		Expect(state.Secret).To(HaveKeyWithValue("AWS_ENDPOINT_URL", []byte("http://local-s3:9000")))
+		Expect(state.Secret).To(HaveKeyWithValue("BUCKET_NAME", []byte("dev-bucket")))
+	})
+
+	It("refuses to delete a bucket that still has objects", func() {
+		manager := New(root, "")
+		s3bkt := bucket("dev-bucket")
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(root, "dev-bucket", "object"), []byte("data"), 0o644)).To(Succeed())
+
+		Expect(manager.Delete(ctx, s3bkt)).To(MatchError("bucket dev-bucket is not empty"))
+
+		Expect(os.Remove(filepath.Join(root, "dev-bucket", "object"))).To(Succeed())
+		Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+		Expect(filepath.Join(root, "dev-bucket")).NotTo(BeADirectory())
+
+		By("treating a missing bucket as deleted")
+		Expect(manager.Delete(ctx, s3bkt)).To(Succeed())
+	})
+
+	It("measures the objects of a bucket", func() {
+		manager := New(root, "")
+		s3bkt := bucket("dev-bucket")
+		_, err := manager.Ensure(ctx, s3bkt)
+		Expect(err).NotTo(HaveOccurred())
+		dir := filepath.Join(root, "dev-bucket")
+		Expect(os.MkdirAll(filepath.Join(dir, "logs"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "object"), []byte("data"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "logs", "today"), []byte("line\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, uploadPrefix+"123"), []byte("partial"), 0o644)).To(Succeed())
+
+		usage, err := manager.Usage(ctx, s3bkt, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.ObjectCount).To(BeEquivalentTo(2))
+		Expect(usage.SizeBytes).To(BeEquivalentTo(9))
+		Expect(usage.Source).To(Equal(s3v1alpha1.UsageSourceProvider))
+		Expect(usage.Truncated).To(BeFalse())
+
+		By("stopping at the bound")
+		usage, err = manager.Usage(ctx, s3bkt, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.ObjectCount).To(BeEquivalentTo(1))
+		Expect(usage.Truncated).To(BeTrue())
+	})
+
+	It("checks that the root directory exists", func() {
+		Expect(New(root, "").Check(ctx)).To(Succeed())
+		Expect(New(filepath.Join(root, "missing"), "").Check(ctx)).To(MatchError(ContainSubstring("bucket root is not accessible")))
+	})
+
+	It("rejects names escaping the root", func() {
+		for _, name := range []string{"..", "../outside", "nested/bucket"} {
+			_, err := New(root, "").Ensure(ctx, bucket(name))
+			Expect(err).To(MatchError(ContainSubstring("invalid bucket name")))
+		}
+	})
+})
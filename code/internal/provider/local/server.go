@@ -0,0 +1,539 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// s3Namespace is the XML namespace of S3 responses
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	// uploadPrefix prefixes the temporary files of uploads in progress, hidden from listings
+	uploadPrefix = ".kube-s3-upload-"
+	// defaultMaxKeys is the page size of listings
+	defaultMaxKeys = 1000
+	// timeFormat is the timestamp format of S3 XML responses
+	timeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// Server serves the buckets under Root over a minimal, path-style S3 API: listing buckets and
+// objects, and putting, getting and deleting objects. Requests are not authenticated, so it is
+// meant for development clusters only
+type Server struct {
+	// Addr is the address the server listens on, e.g. :9000
+	Addr string
+	// Root is the directory holding one directory per bucket
+	Root string
+}
+
+// Start serves until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	server := &http.Server{Addr: s.Addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Serving local buckets over the S3 API", "Addr", s.Addr, "Root", s.Root)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection is false so that every replica serves the buckets
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP routes path-style S3 requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	if bucket == "" {
+		if req.Method != http.MethodGet {
+			writeError(w, req, http.StatusNotImplemented, "NotImplemented", "Only ListBuckets is implemented on /")
+			return
+		}
+		s.listBuckets(w, req)
+		return
+	}
+
+	dir, err := bucketDir(s.Root, bucket)
+	if err != nil {
+		writeError(w, req, http.StatusBadRequest, "InvalidBucketName", err.Error())
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		writeError(w, req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	query := req.URL.Query()
+	switch {
+	case key == "" && req.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && req.Method == http.MethodGet && query.Has("location"):
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+			Xmlns   string   `xml:"xmlns,attr"`
+		}{Xmlns: s3Namespace})
+	case key == "" && req.Method == http.MethodGet:
+		s.listObjects(w, req, bucket, dir)
+	case key == "" && req.Method == http.MethodPost && query.Has("delete"):
+		s.deleteObjects(w, req, dir)
+	case key == "":
+		writeError(w, req, http.StatusNotImplemented, "NotImplemented", "Buckets are managed through S3Bucket resources")
+	case req.Method == http.MethodPut && req.Header.Get("x-amz-copy-source") == "" && !query.Has("uploadId"):
+		s.putObject(w, req, dir, key)
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		s.getObject(w, req, dir, key)
+	case req.Method == http.MethodDelete && !query.Has("uploadId"):
+		s.deleteObject(w, req, dir, key)
+	default:
+		writeError(w, req, http.StatusNotImplemented, "NotImplemented", "The local S3 endpoint does not implement this operation")
+	}
+}
+
+// listBuckets lists the bucket directories
+func (s *Server) listBuckets(w http.ResponseWriter, req *http.Request) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	type bucketEntry struct {
+		Name         string
+		CreationDate string
+	}
+	result := struct {
+		XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Owner   struct{ ID string }
+		Buckets []bucketEntry `xml:"Buckets>Bucket"`
+	}{Xmlns: s3Namespace}
+	result.Owner.ID = developmentCredential
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		result.Buckets = append(result.Buckets, bucketEntry{Name: entry.Name(), CreationDate: info.ModTime().UTC().Format(timeFormat)})
+	}
+	writeXML(w, result)
+}
+
+type objectEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+type commonPrefix struct {
+	Prefix string
+}
+
+// listObjects implements ListObjects and ListObjectsV2 with prefix, delimiter and pagination
+func (s *Server) listObjects(w http.ResponseWriter, req *http.Request, bucket, dir string) {
+	query := req.URL.Query()
+	v2 := query.Get("list-type") == "2"
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	maxKeys := defaultMaxKeys
+	if value := query.Get("max-keys"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeError(w, req, http.StatusBadRequest, "InvalidArgument", "max-keys must be a non-negative integer")
+			return
+		}
+		maxKeys = min(parsed, defaultMaxKeys)
+	}
+	marker := query.Get("marker")
+	if v2 {
+		marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			marker = token
+		}
+	}
+
+	keys, err := objectKeys(dir)
+	if err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Xmlns                 string   `xml:"xmlns,attr"`
+		Name                  string
+		Prefix                string
+		Delimiter             string `xml:",omitempty"`
+		Marker                string `xml:",omitempty"`
+		NextMarker            string `xml:",omitempty"`
+		StartAfter            string `xml:",omitempty"`
+		ContinuationToken     string `xml:",omitempty"`
+		NextContinuationToken string `xml:",omitempty"`
+		KeyCount              int    `xml:",omitempty"`
+		MaxKeys               int
+		IsTruncated           bool
+		Contents              []objectEntry
+		CommonPrefixes        []commonPrefix
+	}{Xmlns: s3Namespace, Name: bucket, Prefix: prefix, Delimiter: delimiter, MaxKeys: maxKeys}
+	if v2 {
+		result.StartAfter, result.ContinuationToken = query.Get("start-after"), query.Get("continuation-token")
+	} else {
+		result.Marker = marker
+	}
+
+	var last string
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		entry := key
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry = key[:len(prefix)+i+len(delimiter)]
+				if entry <= marker || seen[entry] {
+					continue
+				}
+			}
+		}
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+
+		if entry != key {
+			seen[entry] = true
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: entry})
+		} else {
+			object, err := objectInfo(dir, key)
+			if err != nil {
+				writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+				return
+			}
+			result.Contents = append(result.Contents, *object)
+		}
+		result.KeyCount++
+		last = entry
+	}
+	if result.IsTruncated {
+		if v2 {
+			result.NextContinuationToken = last
+		} else {
+			result.NextMarker = last
+		}
+	}
+	if !v2 {
+		result.KeyCount = 0
+	}
+	writeXML(w, result)
+}
+
+// putObject writes an object atomically
+func (s *Server) putObject(w http.ResponseWriter, req *http.Request, dir, key string) {
+	path, err := objectPath(dir, key)
+	if err != nil {
+		writeError(w, req, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), uploadPrefix)
+	if err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	defer os.Remove(file.Name())
+
+	hash := md5.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), payload(req))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.Header().Set("ETag", quoteETag(hash.Sum(nil)))
+	w.WriteHeader(http.StatusOK)
+}
+
+// getObject serves an object, including range and conditional requests
+func (s *Server) getObject(w http.ResponseWriter, req *http.Request, dir, key string) {
+	path, err := objectPath(dir, key)
+	if err != nil {
+		writeError(w, req, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		writeError(w, req, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		writeError(w, req, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.Header().Set("ETag", quoteETag(hash.Sum(nil)))
+	http.ServeContent(w, req, key, info.ModTime(), file)
+}
+
+// deleteObject removes an object and the directories it leaves empty. Missing objects are not an error
+func (s *Server) deleteObject(w http.ResponseWriter, req *http.Request, dir, key string) {
+	if err := removeObject(dir, key); err != nil {
+		writeError(w, req, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteObjects implements DeleteObjects
+func (s *Server) deleteObjects(w http.ResponseWriter, req *http.Request, dir string) {
+	var request struct {
+		Quiet   bool
+		Objects []struct{ Key string } `xml:"Object"`
+	}
+	if err := xml.NewDecoder(req.Body).Decode(&request); err != nil {
+		writeError(w, req, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	type deleteError struct {
+		Key     string
+		Code    string
+		Message string
+	}
+	result := struct {
+		XMLName xml.Name      `xml:"DeleteResult"`
+		Xmlns   string        `xml:"xmlns,attr"`
+		Deleted []commonKey   `xml:"Deleted"`
+		Errors  []deleteError `xml:"Error"`
+	}{Xmlns: s3Namespace}
+	for _, object := range request.Objects {
+		if err := removeObject(dir, object.Key); err != nil {
+			result.Errors = append(result.Errors, deleteError{Key: object.Key, Code: "InternalError", Message: err.Error()})
+		} else if !request.Quiet {
+			result.Deleted = append(result.Deleted, commonKey{Key: object.Key})
+		}
+	}
+	writeXML(w, result)
+}
+
+type commonKey struct {
+	Key string
+}
+
+// removeObject removes an object and the directories it leaves empty
+func removeObject(dir, key string) error {
+	path, err := objectPath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for parent := filepath.Dir(path); parent != dir; parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// objectKeys returns the sorted keys of the objects stored in a bucket directory
+func objectKeys(dir string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), uploadPrefix) {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(relative))
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// objectInfo describes an object for listings
+func objectInfo(dir, key string) (*objectEntry, error) {
+	path := filepath.Join(dir, filepath.FromSlash(key))
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return &objectEntry{
+		Key:          key,
+		LastModified: info.ModTime().UTC().Format(timeFormat),
+		ETag:         quoteETag(hash.Sum(nil)),
+		Size:         info.Size(),
+		StorageClass: "STANDARD",
+	}, nil
+}
+
+// objectPath returns the file of an object, rejecting keys that escape the bucket directory
+func objectPath(dir, key string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) || strings.HasSuffix(key, "/") ||
+		strings.HasPrefix(filepath.Base(path), uploadPrefix) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return path, nil
+}
+
+// payload returns the object data of a PutObject request, decoding the aws-chunked encoding
+// newer SDKs use to stream signed chunks and trailing checksums
+func payload(req *http.Request) io.Reader {
+	if strings.HasPrefix(req.Header.Get("x-amz-content-sha256"), "STREAMING-") ||
+		strings.Contains(req.Header.Get("Content-Encoding"), "aws-chunked") {
+		return &chunkedReader{reader: bufio.NewReader(req.Body)}
+	}
+	return req.Body
+}
+
+// chunkedReader decodes the aws-chunked encoding: chunks of "<hex size>[;chunk-signature=...]\r\n
+// <data>\r\n", ending with a zero-sized chunk optionally followed by trailers
+type chunkedReader struct {
+	reader    *bufio.Reader
+	remaining int64
+	started   bool
+	done      bool
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	for c.remaining == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if c.started {
+			// The CRLF ending the previous chunk
+			if _, err := c.reader.Discard(2); err != nil {
+				return 0, io.ErrUnexpectedEOF
+			}
+		}
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		size, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		c.remaining, err = strconv.ParseInt(size, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed aws-chunked encoding: %w", err)
+		}
+		c.started = true
+		c.done = c.remaining == 0
+	}
+
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.reader.Read(p)
+	c.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && c.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// quoteETag formats an MD5 digest as an S3 ETag
+func quoteETag(sum []byte) string {
+	return `"` + hex.EncodeToString(sum) + `"`
+}
+
+// writeXML writes an S3 XML response
+func writeXML(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an S3 error response, without a body for HEAD requests
+func writeError(w http.ResponseWriter, req *http.Request, status int, code, message string) {
+	if req.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string
+		Message  string
+		Resource string
+	}{Code: code, Message: message, Resource: req.URL.Path})
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Local S3 Server", func() {
+	var (
+		root   string
+		server *httptest.Server
+		client *s3.S3
+	)
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(root, "dev-bucket"), 0o755)).To(Succeed())
+		server = httptest.NewServer(&Server{Root: root})
+		DeferCleanup(server.Close)
+
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String(defaultRegion),
+			S3ForcePathStyle: aws.Bool(true),
+			Credentials:      credentials.NewStaticCredentials(developmentCredential, developmentCredential, ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		client = s3.New(sess)
+	})
+
+	put := func(key, body string) {
+		_, err := client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String("dev-bucket"),
+			Key:    aws.String(key),
+			Body:   strings.NewReader(body),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("lists the buckets", func() {
+		out, err := client.ListBuckets(&s3.ListBucketsInput{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Buckets).To(HaveLen(1))
+		Expect(aws.StringValue(out.Buckets[0].Name)).To(Equal("dev-bucket"))
+
+		_, err = client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("dev-bucket")})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("missing-bucket")})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("stores, reads and deletes objects", func() {
+		put("reports/2025/january.csv", "a,b,c")
+
+		out, err := client.GetObject(&s3.GetObjectInput{Bucket: aws.String("dev-bucket"), Key: aws.String("reports/2025/january.csv")})
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(out.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("a,b,c"))
+		Expect(aws.StringValue(out.ETag)).To(Equal(fmt.Sprintf(`"%x"`, md5.Sum(body))))
+		Expect(aws.Int64Value(out.ContentLength)).To(BeEquivalentTo(5))
+
+		ranged, err := client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String("dev-bucket"), Key: aws.String("reports/2025/january.csv"), Range: aws.String("bytes=2-"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		body, err = io.ReadAll(ranged.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("b,c"))
+
+		_, err = client.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String("dev-bucket"), Key: aws.String("reports/2025/january.csv")})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetObject(&s3.GetObjectInput{Bucket: aws.String("dev-bucket"), Key: aws.String("reports/2025/january.csv")})
+		var awsErr awserr.Error
+		Expect(errors.As(err, &awsErr)).To(BeTrue())
+		Expect(awsErr.Code()).To(Equal(s3.ErrCodeNoSuchKey))
+
+		By("removing the directories the object leaves empty")
+		entries, err := os.ReadDir(filepath.Join(root, "dev-bucket"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("lists objects with prefixes, delimiters and pages", func() {
+		for _, key := range []string{"a.txt", "logs/1.log", "logs/2.log", "logs/archive/3.log", "z.txt"} {
+			put(key, key)
+		}
+
+		out, err := client.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String("dev-bucket"), Delimiter: aws.String("/")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(out.Contents)).To(Equal([]string{"a.txt", "z.txt"}))
+		Expect(out.CommonPrefixes).To(HaveLen(1))
+		Expect(aws.StringValue(out.CommonPrefixes[0].Prefix)).To(Equal("logs/"))
+
+		out, err = client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket: aws.String("dev-bucket"), Prefix: aws.String("logs/"), Delimiter: aws.String("/"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(out.Contents)).To(Equal([]string{"logs/1.log", "logs/2.log"}))
+		Expect(aws.StringValue(out.CommonPrefixes[0].Prefix)).To(Equal("logs/archive/"))
+
+		var pages [][]string
+		Expect(client.ListObjectsV2Pages(&s3.ListObjectsV2Input{Bucket: aws.String("dev-bucket"), MaxKeys: aws.Int64(2)},
+			func(page *s3.ListObjectsV2Output, _ bool) bool {
+				pages = append(pages, keys(page.Contents))
+				return true
+			})).To(Succeed())
+		Expect(pages).To(Equal([][]string{{"a.txt", "logs/1.log"}, {"logs/2.log", "logs/archive/3.log"}, {"z.txt"}}))
+
+		v1, err := client.ListObjects(&s3.ListObjectsInput{Bucket: aws.String("dev-bucket"), Marker: aws.String("logs/2.log")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(v1.Contents)).To(Equal([]string{"logs/archive/3.log", "z.txt"}))
+	})
+
+	It("deletes objects in batches", func() {
+		put("one", "1")
+		put("two", "2")
+
+		out, err := client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String("dev-bucket"),
+			Delete: &s3.Delete{Objects: []*s3.ObjectIdentifier{{Key: aws.String("one")}, {Key: aws.String("two")}}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Deleted).To(HaveLen(2))
+		Expect(out.Errors).To(BeEmpty())
+
+		list, err := client.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String("dev-bucket")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Contents).To(BeEmpty())
+	})
+
+	It("decodes aws-chunked uploads", func() {
+		body := "5;chunk-signature=abc\r\nhello\r\n6;chunk-signature=def\r\n world\r\n0;chunk-signature=ghi\r\n\r\n"
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/dev-bucket/greeting", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("x-amz-content-sha256", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		Expect(os.ReadFile(filepath.Join(root, "dev-bucket", "greeting"))).To(Equal([]byte("hello world")))
+	})
+
+	It("keeps object keys inside the bucket", func() {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/dev-bucket/..%2F..%2Fescaped", bytes.NewReader([]byte("data")))
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(filepath.Join(filepath.Dir(root), "escaped")).NotTo(BeAnExistingFile())
+	})
+
+	It("reports bucket operations as not implemented", func() {
+		_, err := client.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String("dev-bucket")})
+		var awsErr awserr.Error
+		Expect(errors.As(err, &awsErr)).To(BeTrue())
+		Expect(awsErr.Code()).To(Equal("NotImplemented"))
+	})
+})
+
+func keys(objects []*s3.Object) []string {
+	var result []string
+	for _, object := range objects {
+		result = append(result, aws.StringValue(object.Key))
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provider defines how the S3Bucket reconciler manages buckets on storage
+// providers other than S3 and S3-compatible endpoints.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// BucketManager creates, configures and deletes the buckets of one provider
+type BucketManager interface {
+	// Ensure creates the bucket when it does not exist and applies the spec to it
+	Ensure(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) (*BucketState, error)
+	// Delete deletes the bucket. A bucket that no longer exists is not an error
+	Delete(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket) error
+}
+
+// Checker is implemented by BucketManagers that can verify they reach the provider with valid credentials
+type Checker interface {
+	// Check makes a cheap authenticated call to the provider
+	Check(ctx context.Context) error
+}
+
+// UsageReporter is implemented by BucketManagers that can measure the storage used by a bucket
+type UsageReporter interface {
+	// Usage returns the size and object count of the bucket, counting at most maxObjects objects
+	// when maxObjects is positive
+	Usage(ctx context.Context, s3bkt *s3v1alpha1.S3Bucket, maxObjects int64) (*s3v1alpha1.BucketUsage, error)
+}
+
+// CredentialIssuer is implemented by BucketManagers that publish short-lived credentials scoped
+// to the bucket rather than the credentials of the provider
+type CredentialIssuer interface {
+	// IssueCredentials signs new credentials for the bucket, valid from now, without calling the
+	// provider. They are published in the bucket credentials Secret until they expire
+	IssueCredentials(s3bkt *s3v1alpha1.S3Bucket, now time.Time) (credentials map[string][]byte, expiry time.Time, err error)
+}
+
+// BucketState is the bucket as observed after Ensure
+type BucketState struct {
+	// Location is the region or location the bucket lives in
+	Location string
+	// URL addresses the bucket, published as the location key of the bucket ConfigMap
+	URL string
+	// Unsupported lists the spec fields that were requested but that the provider does not implement
+	Unsupported []string
+	// ConnectionDetails are additional keys published in the bucket ConfigMap
+	ConnectionDetails map[string]string
+	// Secret holds the connection details published in the bucket credentials Secret when the
+	// provider has any. It never holds credentials of the whole provider account
+	Secret map[string][]byte
+}
+
+// genericFields are handled by the reconciler whatever the provider
+var genericFields = map[string]bool{
+	"name":                    true,
+	"region":                  true,
+	"providerConfigRef":       true,
+	"driftPolicy":             true,
+	"allowedAccessNamespaces": true,
+	"connectionDetails":       true,
+}
+
+// UnsupportedFields returns, sorted, the spec fields that are set but neither generic nor supported
+func UnsupportedFields(spec s3v1alpha1.S3BucketSpec, supported ...string) []string {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+
+	allowed := map[string]bool{}
+	for _, field := range supported {
+		allowed[field] = true
+	}
+	var unsupported []string
+	for field := range fields {
+		if !genericFields[field] && !allowed[field] {
+			unsupported = append(unsupported, field)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("UnsupportedFields", func() {
+	It("ignores generic and unset fields", func() {
+		Expect(UnsupportedFields(s3v1alpha1.S3BucketSpec{
+			Name:              "bucket",
+			Region:            "europe-west1",
+			ProviderConfigRef: "gcs",
+			DriftPolicy:       s3v1alpha1.DriftPolicyReport,
+		})).To(BeEmpty())
+	})
+
+	It("lists requested fields that are not supported, sorted", func() {
+		Expect(UnsupportedFields(s3v1alpha1.S3BucketSpec{
+			Name:          "bucket",
+			Versioning:    true,
+			Acceleration:  true,
+			RequesterPays: true,
+			Inventory:     []s3v1alpha1.InventoryConfiguration{{ID: "daily"}},
+		}, "versioning", "requesterPays")).To(Equal([]string{"acceleration", "inventory"}))
+	})
+})
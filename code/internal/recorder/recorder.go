@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recorder emits the Kubernetes Events of the operator. Warnings caused by AWS errors
+// carry the AWS error code and request ID, so kubectl describe explains failures without logs.
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the S3Bucket lifecycle events
+const (
+	ReasonCreating         = "Creating"
+	ReasonCreated          = "Created"
+	ReasonCreateFailed     = "CreateFailed"
+	ReasonConfigApplied    = "ConfigApplied"
+	ReasonConfigFailed     = "ConfigFailed"
+	ReasonDriftDetected    = "DriftDetected"
+	ReasonDeletionBlocked  = "DeletionBlocked"
+	ReasonFinalizerRemoved = "FinalizerRemoved"
+	ReasonPaused           = "Paused"
+	ReasonResumed          = "Resumed"
+)
+
+// Recorder emits Normal and Warning events. A nil Recorder, or one without an EventRecorder,
+// emits nothing
+type Recorder struct {
+	recorder record.EventRecorder
+}
+
+// New returns a Recorder emitting events through recorder
+func New(recorder record.EventRecorder) *Recorder {
+	return &Recorder{recorder: recorder}
+}
+
+// Normal emits a Normal event
+func (r *Recorder) Normal(obj runtime.Object, reason, messageFmt string, args ...interface{}) {
+	if r == nil || r.recorder == nil {
+		return
+	}
+	r.recorder.Eventf(obj, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warning emits a Warning event for err, prefixed by message
+func (r *Recorder) Warning(obj runtime.Object, reason, message string, err error) {
+	if r == nil || r.recorder == nil {
+		return
+	}
+	r.recorder.Event(obj, corev1.EventTypeWarning, reason, message+": "+Summarize(err))
+}
+
+// Summarize describes err on one line. AWS errors end with their error code and, when AWS
+// answered, the request ID
+func Summarize(err error) string {
+	text := err.Error()
+	// AWS errors put the status code and request ID on extra lines
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+
+	var requestFailure awserr.RequestFailure
+	if errors.As(err, &requestFailure) && requestFailure.RequestID() != "" {
+		return fmt.Sprintf("%s (AWS error code: %s, request ID: %s)", text, requestFailure.Code(), requestFailure.RequestID())
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return fmt.Sprintf("%s (AWS error code: %s)", text, awsErr.Code())
+	}
+	return text
+}
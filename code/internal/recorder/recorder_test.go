@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		fakeRecorder *record.FakeRecorder
+		obj          *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		fakeRecorder = record.NewFakeRecorder(10)
+		obj = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}}
+	})
+
+	It("should emit Normal events", func() {
+		New(fakeRecorder).Normal(obj, ReasonCreated, "Bucket %s is ready", "my-data")
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal Created Bucket my-data is ready")))
+	})
+
+	It("should add the AWS error code and request ID to Warning events", func() {
+		err := fmt.Errorf("failed to create S3 bucket: %w",
+			awserr.NewRequestFailure(awserr.New("BucketAlreadyExists", "The requested bucket name is not available", nil), 409, "4442587FB7D0A2F9"))
+
+		New(fakeRecorder).Warning(obj, ReasonCreateFailed, "Failed to create bucket my-data", err)
+		Expect(fakeRecorder.Events).To(Receive(Equal("Warning CreateFailed Failed to create bucket my-data: " +
+			"failed to create S3 bucket: BucketAlreadyExists: The requested bucket name is not available " +
+			"(AWS error code: BucketAlreadyExists, request ID: 4442587FB7D0A2F9)")))
+	})
+
+	It("should describe errors that did not reach AWS", func() {
+		Expect(Summarize(awserr.New("RequestCanceled", "request context canceled", nil))).
+			To(Equal("RequestCanceled: request context canceled (AWS error code: RequestCanceled)"))
+		Expect(Summarize(errors.New("bucket my-data is not empty"))).To(Equal("bucket my-data is not empty"))
+	})
+
+	It("should emit nothing without an EventRecorder", func() {
+		var nilRecorder *Recorder
+		nilRecorder.Normal(obj, ReasonCreated, "Bucket is ready")
+		New(nil).Warning(obj, ReasonCreateFailed, "Failed", errors.New("boom"))
+	})
+})
@@ -0,0 +1,503 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package s3client builds and caches the clients used to reach the buckets of each S3ProviderConfig.
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/metrics"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/azure"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/gcs"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider/local"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/tracing"
+)
+
+const (
+	// fallbackKey is the cache key of the clients built from the operator's own session
+	fallbackKey = ""
+	// roleSessionName identifies the operator in CloudTrail when it assumes a role
+	roleSessionName = "kube-s3-operator"
+)
+
+// Clients are the service clients of one provider config. AWS and S3-compatible
+// configs get the AWS clients, other providers a bucket manager
+type Clients struct {
+	// Provider is the type of the provider config, e.g. AWS or GCS
+	Provider string
+
+	// Version identifies the revision of the provider config and credentials the clients were
+	// built from. It is empty for the operator credentials
+	Version string
+
+	S3  *s3.S3
+	IAM *iam.IAM
+	STS *sts.STS
+
+	// Manager manages the buckets of providers other than AWS
+	Manager provider.BucketManager
+
+	// awsSession is the session of clients reaching AWS itself, e.g. for CloudWatch and STS.
+	// It is nil for S3-compatible endpoints
+	awsSession *session.Session
+
+	mu         sync.Mutex
+	accountID  string
+	cloudWatch map[string]*cloudwatch.CloudWatch
+	regionalS3 map[string]*s3.S3
+}
+
+// AccountID returns the AWS account the clients act in, looked up once through STS.
+// It is empty for S3-compatible endpoints and providers other than AWS, which have no STS
+func (c *Clients) AccountID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accountID != "" || c.STS == nil || c.awsSession == nil {
+		return c.accountID, nil
+	}
+	identity, err := c.STS.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("STS GetCallerIdentity API call failed: %w", err)
+	}
+	c.accountID = aws.StringValue(identity.Account)
+	return c.accountID, nil
+}
+
+// CloudWatch returns a CloudWatch client in region, or nil when the clients reach an
+// S3-compatible endpoint rather than AWS
+func (c *Clients) CloudWatch(region string) *cloudwatch.CloudWatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.awsSession == nil {
+		return nil
+	}
+	if c.cloudWatch == nil {
+		c.cloudWatch = map[string]*cloudwatch.CloudWatch{}
+	}
+	if _, ok := c.cloudWatch[region]; !ok {
+		c.cloudWatch[region] = cloudwatch.New(c.awsSession, aws.NewConfig().WithRegion(region))
+	}
+	return c.cloudWatch[region]
+}
+
+// S3In returns an S3 client in region, as buckets are created in and managed through the region
+// of the client. S3-compatible endpoints have a single region and always get S3
+func (c *Clients) S3In(region string) *s3.S3 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.awsSession == nil || region == "" || region == aws.StringValue(c.S3.Config.Region) {
+		return c.S3
+	}
+	if c.regionalS3 == nil {
+		c.regionalS3 = map[string]*s3.S3{}
+	}
+	if _, ok := c.regionalS3[region]; !ok {
+		c.regionalS3[region] = newS3Client(c.awsSession, aws.NewConfig().WithRegion(region))
+	}
+	return c.regionalS3[region]
+}
+
+// newS3Client returns an S3 client of sess reporting metrics and traces
+func newS3Client(sess *session.Session, configs ...*aws.Config) *s3.S3 {
+	client := s3.New(sess, configs...)
+	client.Handlers.Complete.PushBackNamed(metrics.S3Handler)
+	tracing.InstrumentAWS(&client.Handlers)
+	return client
+}
+
+// assumeRole is an IAM role assumed on top of the credentials of a provider config
+type assumeRole struct {
+	arn        string
+	externalID string
+}
+
+type entry struct {
+	// fingerprint changes whenever the provider config or its credentials Secret change
+	fingerprint string
+	clients     *Clients
+}
+
+// Cache hands out AWS clients per S3ProviderConfig and rebuilds them when the
+// config or its credentials Secret change
+type Cache struct {
+	reader   client.Reader
+	fallback *session.Session
+
+	mu      sync.Mutex
+	entries map[string]entry
+	// generation counts the replacements of the fallback session
+	generation int
+}
+
+// NewCache returns a Cache reading provider configs and Secrets through reader. The fallback
+// session, which may be nil, provides the operator's own credentials and is used when a bucket
+// references no provider config and no default one exists.
+func NewCache(reader client.Reader, fallback *session.Session) *Cache {
+	return &Cache{
+		reader:   reader,
+		fallback: fallback,
+		entries:  map[string]entry{},
+	}
+}
+
+// Get returns the clients for a bucket in namespace, using the named provider config or the
+// default one when name is empty. On AWS, the role annotated on the namespace, or else the role
+// of the provider config, is assumed through STS; its temporary credentials refresh automatically.
+func (c *Cache) Get(ctx context.Context, name, namespace string) (*Clients, error) {
+	config, err := c.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := c.namespaceRole(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil && config != nil && config.Spec.RoleARN != "" {
+		role = &assumeRole{arn: config.Spec.RoleARN, externalID: config.Spec.ExternalID}
+	}
+
+	fallback, generation := c.currentFallback()
+	key, version := fallbackKey, ""
+	base := func() (*session.Session, error) {
+		return fallback, nil
+	}
+	if config == nil {
+		if fallback == nil {
+			return nil, fmt.Errorf("no S3ProviderConfig referenced, no default S3ProviderConfig and no operator credentials configured")
+		}
+	} else {
+		var secret *corev1.Secret
+		if ref := config.Spec.CredentialsSecretRef; ref != nil {
+			secret = &corev1.Secret{}
+			if err := c.reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, secret); err != nil {
+				return nil, fmt.Errorf("failed to get credentials Secret %s/%s of S3ProviderConfig %s: %w", ref.Namespace, ref.Name, config.Name, err)
+			}
+		}
+		key, version = config.Name, fingerprint(config, secret)
+		switch config.Spec.Type {
+		case s3v1alpha1.ProviderGCS:
+			return c.cached(key, version, generation, func() (*Clients, error) {
+				return newGCSClients(config, secret)
+			})
+		case s3v1alpha1.ProviderAzure:
+			return c.cached(key, version, generation, func() (*Clients, error) {
+				return newAzureClients(config, secret)
+			})
+		case s3v1alpha1.ProviderLocal:
+			return c.cached(key, version, generation, func() (*Clients, error) {
+				return newLocalClients(config)
+			})
+		}
+		base = func() (*session.Session, error) {
+			return newSession(config, secret, fallback)
+		}
+	}
+
+	if role != nil {
+		key += "|" + role.arn + "|" + role.externalID
+	}
+	return c.cached(key, version, generation, func() (*Clients, error) {
+		sess, err := base()
+		if err != nil {
+			return nil, err
+		}
+		if role != nil {
+			sess = assumeRoleSession(sess, *role)
+		}
+		clients := &Clients{
+			Provider: s3v1alpha1.ProviderAWS,
+			S3:       newS3Client(sess),
+			IAM:      iam.New(sess),
+			STS:      sts.New(sess),
+		}
+		tracing.InstrumentAWS(&clients.IAM.Handlers)
+		tracing.InstrumentAWS(&clients.STS.Handlers)
+		if config == nil || config.Spec.Endpoint == "" {
+			clients.awsSession = sess
+		}
+		return clients, nil
+	})
+}
+
+// SetFallback atomically replaces the operator session, e.g. after its credentials rotated.
+// Every cached client is rebuilt on next use; clients already handed out keep working so
+// in-flight reconciles finish with the credentials they started with.
+func (c *Cache) SetFallback(fallback *session.Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fallback = fallback
+	c.entries = map[string]entry{}
+	c.generation++
+}
+
+// currentFallback returns the operator session and its generation
+func (c *Cache) currentFallback() (*session.Session, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.fallback, c.generation
+}
+
+// namespaceRole returns the role annotated on the namespace, if any
+func (c *Cache) namespaceRole(ctx context.Context, namespace string) (*assumeRole, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := c.reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	roleARN := ns.Annotations[s3v1alpha1.RoleARNAnnotation]
+	if roleARN == "" {
+		return nil, nil
+	}
+	return &assumeRole{arn: roleARN, externalID: ns.Annotations[s3v1alpha1.ExternalIDAnnotation]}, nil
+}
+
+// resolve returns the named provider config, the default one when name is empty, or nil
+// when no name is given and there is no default
+func (c *Cache) resolve(ctx context.Context, name string) (*s3v1alpha1.S3ProviderConfig, error) {
+	if name != "" {
+		config := &s3v1alpha1.S3ProviderConfig{}
+		if err := c.reader.Get(ctx, types.NamespacedName{Name: name}, config); err != nil {
+			return nil, fmt.Errorf("failed to get S3ProviderConfig %s: %w", name, err)
+		}
+		return config, nil
+	}
+
+	configs := &s3v1alpha1.S3ProviderConfigList{}
+	if err := c.reader.List(ctx, configs); err != nil {
+		return nil, fmt.Errorf("failed to list S3ProviderConfig resources: %w", err)
+	}
+	for i := range configs.Items {
+		if configs.Items[i].Annotations[s3v1alpha1.DefaultProviderConfigAnnotation] == "true" {
+			return &configs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// cached returns the clients stored under key, building new ones when the fingerprint changed.
+// Clients built from a fallback session that was replaced meanwhile are returned but not stored.
+func (c *Cache) cached(key, fingerprint string, generation int, build func() (*Clients, error)) (*Clients, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.entries[key]; ok && cached.fingerprint == fingerprint {
+		return cached.clients, nil
+	}
+
+	clients, err := build()
+	if err != nil {
+		return nil, err
+	}
+	clients.Version = fingerprint
+	if generation == c.generation {
+		c.entries[key] = entry{fingerprint: fingerprint, clients: clients}
+	}
+	return clients, nil
+}
+
+// assumeRoleSession returns a copy of sess acting as the given role
+func assumeRoleSession(sess *session.Session, role assumeRole) *session.Session {
+	return sess.Copy(&aws.Config{
+		Credentials: stscreds.NewCredentials(sess, role.arn, func(provider *stscreds.AssumeRoleProvider) {
+			provider.RoleSessionName = roleSessionName
+			if role.externalID != "" {
+				provider.ExternalID = aws.String(role.externalID)
+			}
+		}),
+	})
+}
+
+// fingerprint identifies a revision of a provider config and the credentials of its Secret
+func fingerprint(config *s3v1alpha1.S3ProviderConfig, secret *corev1.Secret) string {
+	value := fmt.Sprintf("%s/%d", config.UID, config.Generation)
+	if secret != nil {
+		value += "/" + CredentialsVersion(secret)
+	}
+	return value
+}
+
+// CredentialsVersion returns a digest of the credentials held by a Secret, so that
+// unrelated changes such as labels are not mistaken for a rotation
+func CredentialsVersion(secret *corev1.Secret) string {
+	hash := sha256.New()
+	for _, key := range []string{s3v1alpha1.CredentialsAccessKeyIDKey, s3v1alpha1.CredentialsSecretAccessKeyKey,
+		s3v1alpha1.CredentialsSessionTokenKey, s3v1alpha1.GCSCredentialsKey, s3v1alpha1.AzureStorageKeyKey,
+		s3v1alpha1.AzureTenantIDKey, s3v1alpha1.AzureClientIDKey, s3v1alpha1.AzureClientSecretKey} {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(secret.Data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// newSession builds the AWS session of a provider config. Settings it leaves empty are
+// inherited from the fallback session.
+func newSession(config *s3v1alpha1.S3ProviderConfig, secret *corev1.Secret, fallback *session.Session) (*session.Session, error) {
+	awsConfig := aws.NewConfig()
+	if config.Spec.Region != "" {
+		awsConfig.WithRegion(config.Spec.Region)
+	}
+	if config.Spec.Endpoint != "" {
+		awsConfig.WithEndpoint(config.Spec.Endpoint)
+	}
+	if config.Spec.ForcePathStyle {
+		awsConfig.WithS3ForcePathStyle(true)
+	}
+	if config.Spec.UseDualStack {
+		awsConfig.UseDualStackEndpoint = endpoints.DualStackEndpointStateEnabled
+	}
+	if config.Spec.UseFIPS {
+		awsConfig.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	if config.Spec.CABundle != "" || config.Spec.InsecureSkipVerify {
+		httpClient, err := newHTTPClient(config.Spec.CABundle, config.Spec.InsecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings in S3ProviderConfig %s: %w", config.Name, err)
+		}
+		awsConfig.WithHTTPClient(httpClient)
+	}
+
+	if secret != nil {
+		accessKeyID := string(secret.Data[s3v1alpha1.CredentialsAccessKeyIDKey])
+		secretAccessKey := string(secret.Data[s3v1alpha1.CredentialsSecretAccessKeyKey])
+		if accessKeyID == "" || secretAccessKey == "" {
+			return nil, fmt.Errorf("credentials Secret %s/%s must contain %s and %s", secret.Namespace, secret.Name,
+				s3v1alpha1.CredentialsAccessKeyIDKey, s3v1alpha1.CredentialsSecretAccessKeyKey)
+		}
+		awsConfig.WithCredentials(credentials.NewStaticCredentials(accessKeyID, secretAccessKey,
+			string(secret.Data[s3v1alpha1.CredentialsSessionTokenKey])))
+	}
+
+	if fallback != nil {
+		return fallback.Copy(awsConfig), nil
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AWS session for S3ProviderConfig %s: %w", config.Name, err)
+	}
+	return sess, nil
+}
+
+// newGCSClients builds the bucket manager of a GCS provider config
+func newGCSClients(config *s3v1alpha1.S3ProviderConfig, secret *corev1.Secret) (*Clients, error) {
+	gcsConfig := gcs.Config{Endpoint: config.Spec.Endpoint}
+	if config.Spec.GCS != nil {
+		gcsConfig.Project = config.Spec.GCS.Project
+		gcsConfig.FineGrainedAccess = config.Spec.GCS.UniformBucketLevelAccess != nil && !*config.Spec.GCS.UniformBucketLevelAccess
+	}
+	if secret != nil {
+		gcsConfig.CredentialsJSON = secret.Data[s3v1alpha1.GCSCredentialsKey]
+		if len(gcsConfig.CredentialsJSON) == 0 {
+			return nil, fmt.Errorf("credentials Secret %s/%s must contain %s", secret.Namespace, secret.Name, s3v1alpha1.GCSCredentialsKey)
+		}
+	}
+	if config.Spec.CABundle != "" || config.Spec.InsecureSkipVerify {
+		httpClient, err := newHTTPClient(config.Spec.CABundle, config.Spec.InsecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings in S3ProviderConfig %s: %w", config.Name, err)
+		}
+		gcsConfig.HTTPClient = httpClient
+	}
+
+	manager, err := gcs.New(gcsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCS client for S3ProviderConfig %s: %w", config.Name, err)
+	}
+	return &Clients{Provider: s3v1alpha1.ProviderGCS, Manager: manager}, nil
+}
+
+// newAzureClients builds the bucket manager of an Azure provider config. The shared key of the
+// storage account is required; Azure Resource Manager is used when the config locates the account
+func newAzureClients(config *s3v1alpha1.S3ProviderConfig, secret *corev1.Secret) (*Clients, error) {
+	if config.Spec.Azure == nil {
+		return nil, fmt.Errorf("S3ProviderConfig %s of type Azure has no azure settings", config.Name)
+	}
+	if secret == nil || len(secret.Data[s3v1alpha1.AzureStorageKeyKey]) == 0 {
+		return nil, fmt.Errorf("S3ProviderConfig %s needs a credentials Secret with %s", config.Name, s3v1alpha1.AzureStorageKeyKey)
+	}
+
+	azureConfig := azure.Config{
+		AccountName: config.Spec.Azure.StorageAccount,
+		AccountKey:  string(secret.Data[s3v1alpha1.AzureStorageKeyKey]),
+		Endpoint:    config.Spec.Endpoint,
+	}
+	if config.Spec.Azure.SubscriptionID != "" {
+		azureConfig.Management = &azure.ManagementConfig{
+			Endpoint:       config.Spec.Azure.ManagementEndpoint,
+			AuthorityHost:  config.Spec.Azure.AuthorityHost,
+			SubscriptionID: config.Spec.Azure.SubscriptionID,
+			ResourceGroup:  config.Spec.Azure.ResourceGroup,
+			TenantID:       string(secret.Data[s3v1alpha1.AzureTenantIDKey]),
+			ClientID:       string(secret.Data[s3v1alpha1.AzureClientIDKey]),
+			ClientSecret:   string(secret.Data[s3v1alpha1.AzureClientSecretKey]),
+		}
+	}
+	if config.Spec.CABundle != "" || config.Spec.InsecureSkipVerify {
+		httpClient, err := newHTTPClient(config.Spec.CABundle, config.Spec.InsecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings in S3ProviderConfig %s: %w", config.Name, err)
+		}
+		azureConfig.HTTPClient = httpClient
+	}
+
+	manager, err := azure.New(azureConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Azure client for S3ProviderConfig %s: %w", config.Name, err)
+	}
+	return &Clients{Provider: s3v1alpha1.ProviderAzure, Manager: manager}, nil
+}
+
+// newLocalClients builds the bucket manager of a Local provider config. The directories live in
+// the operator's filesystem, so there are no credentials
+func newLocalClients(config *s3v1alpha1.S3ProviderConfig) (*Clients, error) {
+	if config.Spec.Local == nil {
+		return nil, fmt.Errorf("S3ProviderConfig %s of type Local has no local settings", config.Name)
+	}
+	return &Clients{Provider: s3v1alpha1.ProviderLocal, Manager: local.New(config.Spec.Local.Root, config.Spec.Endpoint)}, nil
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// scheme returns a scheme with the core and operator types
+func scheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+	Expect(s3v1alpha1.AddToScheme(s)).To(Succeed())
+	return s
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		ctx      context.Context
+		fallback *session.Session
+		secret   *corev1.Secret
+		config   *s3v1alpha1.S3ProviderConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		fallback = session.Must(session.NewSession(&aws.Config{
+			Region:      aws.String("us-west-2"),
+			Credentials: credentials.NewStaticCredentials("operator", "operator-secret", ""),
+		}))
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "s3-acme"},
+			Data: map[string][]byte{
+				s3v1alpha1.CredentialsAccessKeyIDKey:     []byte("tenant-a"),
+				s3v1alpha1.CredentialsSecretAccessKeyKey: []byte("tenant-a-secret"),
+			},
+		}
+		config = &s3v1alpha1.S3ProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+			Spec: s3v1alpha1.S3ProviderConfigSpec{
+				Endpoint: "https://s3.tenant-a.example.com",
+				Region:   "eu-central-1",
+				CredentialsSecretRef: &s3v1alpha1.SecretReference{
+					Name:      "tenant-a",
+					Namespace: "s3-acme",
+				},
+			},
+		}
+	})
+
+	newCache := func(fallback *session.Session, objects ...client.Object) (*Cache, client.Client) {
+		c := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(objects...).Build()
+		return NewCache(c, fallback), c
+	}
+
+	accessKeyID := func(clients *Clients) string {
+		value, err := clients.S3.Config.Credentials.Get()
+		Expect(err).NotTo(HaveOccurred())
+		return value.AccessKeyID
+	}
+
+	It("should use the operator credentials without any provider config", func() {
+		cache, _ := newCache(fallback)
+		clients, err := cache.Get(ctx, "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accessKeyID(clients)).To(Equal("operator"))
+		Expect(aws.StringValue(clients.S3.Config.Region)).To(Equal("us-west-2"))
+	})
+
+	It("should fail without any provider config or operator credentials", func() {
+		cache, _ := newCache(nil)
+		_, err := cache.Get(ctx, "", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should build clients from the referenced provider config", func() {
+		cache, _ := newCache(fallback, config, secret)
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accessKeyID(clients)).To(Equal("tenant-a"))
+		Expect(aws.StringValue(clients.S3.Config.Region)).To(Equal("eu-central-1"))
+		Expect(aws.StringValue(clients.S3.Config.Endpoint)).To(Equal("https://s3.tenant-a.example.com"))
+	})
+
+	It("should reach CloudWatch only on AWS", func() {
+		cache, _ := newCache(fallback, config, secret)
+		clients, err := cache.Get(ctx, "", "")
+		Expect(err).NotTo(HaveOccurred())
+		cloudWatch := clients.CloudWatch("eu-west-1")
+		Expect(cloudWatch).NotTo(BeNil())
+		Expect(aws.StringValue(cloudWatch.Config.Region)).To(Equal("eu-west-1"))
+		Expect(clients.CloudWatch("eu-west-1")).To(BeIdenticalTo(cloudWatch))
+
+		clients, err = cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.CloudWatch("eu-central-1")).To(BeNil())
+	})
+
+	It("should reach the region of the bucket on AWS only", func() {
+		cache, _ := newCache(fallback, config, secret)
+		clients, err := cache.Get(ctx, "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.S3In("")).To(BeIdenticalTo(clients.S3))
+		Expect(clients.S3In("us-west-2")).To(BeIdenticalTo(clients.S3))
+		regional := clients.S3In("eu-west-1")
+		Expect(aws.StringValue(regional.Config.Region)).To(Equal("eu-west-1"))
+		Expect(clients.S3In("eu-west-1")).To(BeIdenticalTo(regional))
+
+		clients, err = cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.S3In("eu-west-1")).To(BeIdenticalTo(clients.S3))
+	})
+
+	It("should not look up the account of S3-compatible endpoints", func() {
+		cache, _ := newCache(fallback, config, secret)
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		accountID, err := clients.AccountID(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accountID).To(BeEmpty())
+	})
+
+	It("should use the default provider config when none is referenced", func() {
+		config.Annotations = map[string]string{s3v1alpha1.DefaultProviderConfigAnnotation: "true"}
+		cache, _ := newCache(fallback, config, secret)
+		clients, err := cache.Get(ctx, "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accessKeyID(clients)).To(Equal("tenant-a"))
+	})
+
+	It("should inherit the operator credentials when the config has no Secret", func() {
+		config.Spec.CredentialsSecretRef = nil
+		cache, _ := newCache(fallback, config)
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accessKeyID(clients)).To(Equal("operator"))
+		Expect(aws.StringValue(clients.S3.Config.Region)).To(Equal("eu-central-1"))
+	})
+
+	It("should reuse clients until the credentials Secret changes", func() {
+		cache, c := newCache(fallback, config, secret)
+		first, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		again, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(first))
+
+		By("ignoring changes that leave the credentials alone")
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		secret.Labels = map[string]string{"team": "storage"}
+		Expect(c.Update(ctx, secret)).To(Succeed())
+		relabeled, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(relabeled).To(BeIdenticalTo(first))
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		secret.Data[s3v1alpha1.CredentialsAccessKeyIDKey] = []byte("tenant-a-rotated")
+		Expect(c.Update(ctx, secret)).To(Succeed())
+
+		rotated, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).NotTo(BeIdenticalTo(first))
+		Expect(accessKeyID(rotated)).To(Equal("tenant-a-rotated"))
+	})
+
+	It("should reject a credentials Secret without keys", func() {
+		secret.Data = nil
+		cache, _ := newCache(fallback, config, secret)
+		_, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).To(MatchError(ContainSubstring("must contain")))
+	})
+
+	It("should build a GCS bucket manager for GCS provider configs", func() {
+		config.Spec.Type = s3v1alpha1.ProviderGCS
+		config.Spec.GCS = &s3v1alpha1.GCSProviderSpec{Project: "my-project"}
+		config.Spec.Endpoint = "http://fake-gcs.s3-acme.svc:4443"
+		config.Spec.CredentialsSecretRef = nil
+		cache, _ := newCache(fallback, config)
+
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.Manager).NotTo(BeNil())
+		Expect(clients.S3).To(BeNil())
+		Expect(clients.AccountID(ctx)).To(BeEmpty())
+	})
+
+	It("should reject a GCS credentials Secret without a service account key", func() {
+		config.Spec.Type = s3v1alpha1.ProviderGCS
+		config.Spec.GCS = &s3v1alpha1.GCSProviderSpec{Project: "my-project"}
+		cache, _ := newCache(fallback, config, secret)
+
+		_, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).To(MatchError(ContainSubstring(s3v1alpha1.GCSCredentialsKey)))
+	})
+
+	It("should build an Azure container manager from the storage account key", func() {
+		config.Spec.Type = s3v1alpha1.ProviderAzure
+		config.Spec.Azure = &s3v1alpha1.AzureProviderSpec{StorageAccount: "devstoreaccount1"}
+		secret.Data = map[string][]byte{s3v1alpha1.AzureStorageKeyKey: []byte("c2hhcmVkLWtleQ==")}
+		cache, _ := newCache(fallback, config, secret)
+
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.Manager).NotTo(BeNil())
+		Expect(clients.IAM).To(BeNil())
+	})
+
+	It("should require the storage account key for Azure", func() {
+		config.Spec.Type = s3v1alpha1.ProviderAzure
+		config.Spec.Azure = &s3v1alpha1.AzureProviderSpec{StorageAccount: "devstoreaccount1"}
+		cache, _ := newCache(fallback, config, secret)
+
+		_, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).To(MatchError(ContainSubstring(s3v1alpha1.AzureStorageKeyKey)))
+	})
+
+	It("should build a local bucket manager without credentials", func() {
+		config.Spec.Type = s3v1alpha1.ProviderLocal
+		config.Spec.Local = &s3v1alpha1.LocalProviderSpec{Root: "/var/lib/kube-s3-operator/buckets"}
+		config.Spec.CredentialsSecretRef = nil
+		cache, _ := newCache(fallback, config)
+
+		clients, err := cache.Get(ctx, "tenant-a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients.Manager).NotTo(BeNil())
+		Expect(clients.S3).To(BeNil())
+	})
+
+	Context("When assuming roles", func() {
+		var (
+			server  *httptest.Server
+			assumed []string
+		)
+
+		BeforeEach(func() {
+			assumed = nil
+			// server stubs the STS query API
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.ParseForm()).To(Succeed())
+				switch req.Form.Get("Action") {
+				case "AssumeRole":
+					assumed = append(assumed, req.Form.Get("RoleArn")+"|"+req.Form.Get("ExternalId"))
+					_, _ = fmt.Fprint(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>role-key</AccessKeyId><SecretAccessKey>role-secret</SecretAccessKey><SessionToken>role-token</SessionToken>
+<Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`)
+				case "GetCallerIdentity":
+					_, _ = fmt.Fprint(w, `<GetCallerIdentityResponse><GetCallerIdentityResult>
+<Account>210987654321</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`)
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			DeferCleanup(server.Close)
+			fallback.Config.Endpoint = aws.String(server.URL)
+		})
+
+		It("should assume the role of the provider config", func() {
+			config.Spec.CredentialsSecretRef = nil
+			config.Spec.Endpoint = server.URL
+			config.Spec.RoleARN = "arn:aws:iam::210987654321:role/s3-operator"
+			config.Spec.ExternalID = "acme"
+			cache, _ := newCache(fallback, config)
+
+			clients, err := cache.Get(ctx, "tenant-a", "")
+			Expect(err).NotTo(HaveOccurred())
+			value, err := clients.S3.Config.Credentials.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value.ProviderName).To(Equal(stscreds.ProviderName))
+			Expect(value.AccessKeyID).To(Equal("role-key"))
+			Expect(assumed).To(ConsistOf("arn:aws:iam::210987654321:role/s3-operator|acme"))
+
+			By("not asking the S3-compatible endpoint for the account")
+			accountID, err := clients.AccountID(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accountID).To(BeEmpty())
+		})
+
+		It("should prefer the role annotated on the namespace", func() {
+			config.Spec.CredentialsSecretRef = nil
+			config.Spec.Endpoint = server.URL
+			config.Spec.RoleARN = "arn:aws:iam::210987654321:role/s3-operator"
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: "team-b",
+				Annotations: map[string]string{
+					s3v1alpha1.RoleARNAnnotation:    "arn:aws:iam::123456789012:role/team-b",
+					s3v1alpha1.ExternalIDAnnotation: "team-b",
+				},
+			}}
+			cache, _ := newCache(fallback, config, namespace)
+
+			teamB, err := cache.Get(ctx, "tenant-a", "team-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessKeyID(teamB)).To(Equal("role-key"))
+			other, err := cache.Get(ctx, "tenant-a", "team-c")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessKeyID(other)).To(Equal("role-key"))
+
+			Expect(other).NotTo(BeIdenticalTo(teamB))
+			Expect(assumed).To(ConsistOf(
+				"arn:aws:iam::123456789012:role/team-b|team-b",
+				"arn:aws:iam::210987654321:role/s3-operator|",
+			))
+		})
+	})
+
+	Context("When talking to S3-compatible storage", func() {
+		var (
+			server *httptest.Server
+			paths  []string
+		)
+
+		BeforeEach(func() {
+			paths = nil
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				paths = append(paths, req.Host+req.URL.Path)
+			}))
+			DeferCleanup(server.Close)
+			config.Spec.Endpoint = server.URL
+			config.Spec.ForcePathStyle = true
+		})
+
+		headBucket := func(clients *Clients) error {
+			_, err := clients.S3.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String("media")})
+			return err
+		}
+
+		It("should trust the configured CA bundle and use path-style addressing", func() {
+			config.Spec.CABundle = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+			cache, _ := newCache(fallback, config, secret)
+			clients, err := cache.Get(ctx, "tenant-a", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(headBucket(clients)).To(Succeed())
+			Expect(paths).To(ConsistOf(strings.TrimPrefix(server.URL, "https://") + "/media"))
+		})
+
+		It("should reject the server certificate without the CA bundle", func() {
+			cache, _ := newCache(fallback, config, secret)
+			clients, err := cache.Get(ctx, "tenant-a", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(headBucket(clients)).NotTo(Succeed())
+		})
+
+		It("should skip verification when asked to", func() {
+			config.Spec.InsecureSkipVerify = true
+			cache, _ := newCache(fallback, config, secret)
+			clients, err := cache.Get(ctx, "tenant-a", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(headBucket(clients)).To(Succeed())
+		})
+
+		It("should reject a CA bundle without certificates", func() {
+			config.Spec.CABundle = "not a certificate"
+			cache, _ := newCache(fallback, config, secret)
+			_, err := cache.Get(ctx, "tenant-a", "")
+			Expect(err).To(MatchError(ContainSubstring("caBundle")))
+		})
+	})
+})
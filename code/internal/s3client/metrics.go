@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// OperatorCredentialsSource labels rotations of the operator's own credentials
+const OperatorCredentialsSource = "operator"
+
+var (
+	credentialsRotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_s3_operator_credentials_rotations_total",
+		Help: "Number of credential rotations picked up without a restart, by source",
+	}, []string{"source"})
+
+	credentialsLastRotation = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_s3_operator_credentials_last_rotation_timestamp_seconds",
+		Help: "Unix time of the last credential rotation, by source",
+	}, []string{"source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(credentialsRotations, credentialsLastRotation)
+}
+
+// RecordRotation records that the credentials of source, a provider config name or
+// OperatorCredentialsSource, rotated at the given time
+func RecordRotation(source string, at time.Time) {
+	credentialsRotations.WithLabelValues(source).Inc()
+	credentialsLastRotation.WithLabelValues(source).Set(float64(at.Unix()))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+	"github.com/victorbecerragit/kube-s3-operator/code/internal/provider"
+)
+
+// checkTimeout bounds the checks of all providers, which run concurrently
+const checkTimeout = 5 * time.Second
+
+// ReadinessChecker verifies that every provider config reaches its object store with valid
+// credentials. Results are cached for a TTL so probes do not call the providers every time
+type ReadinessChecker struct {
+	cache *Cache
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// NewReadinessChecker returns a ReadinessChecker using the clients of cache and keeping its
+// results for ttl
+func NewReadinessChecker(cache *Cache, ttl time.Duration) *ReadinessChecker {
+	return &ReadinessChecker{cache: cache, ttl: ttl}
+}
+
+// Check implements healthz.Checker. Its error names every provider config that is unreachable
+// or rejects its credentials; it is served on /readyz/<check name>
+func (c *ReadinessChecker) Check(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checked.IsZero() && time.Since(c.checked) < c.ttl {
+		return c.err
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+	defer cancel()
+	c.err = c.checkProviders(ctx)
+	c.checked = time.Now()
+	if c.err != nil {
+		logf.FromContext(ctx).WithName("readiness").Info("Object store check failed", "Error", c.err.Error())
+	}
+	return c.err
+}
+
+// checkProviders checks every provider config, and the operator's own credentials when they
+// are used by buckets without a provider config
+func (c *ReadinessChecker) checkProviders(ctx context.Context) error {
+	configs := &s3v1alpha1.S3ProviderConfigList{}
+	if err := c.cache.reader.List(ctx, configs); err != nil {
+		return fmt.Errorf("failed to list S3ProviderConfig resources: %w", err)
+	}
+
+	targets := map[string]string{}
+	hasDefault := false
+	for _, config := range configs.Items {
+		targets["S3ProviderConfig "+config.Name] = config.Name
+		hasDefault = hasDefault || config.Annotations[s3v1alpha1.DefaultProviderConfigAnnotation] == "true"
+	}
+	if fallback, _ := c.cache.currentFallback(); fallback != nil && !hasDefault {
+		targets["operator credentials"] = ""
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []string
+	)
+	for target, name := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.checkProvider(ctx, name); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				failures = append(failures, fmt.Sprintf("%s: %v", target, err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Strings(failures)
+	return errors.New(strings.Join(failures, "; "))
+}
+
+// checkProvider makes a cheap authenticated call with the clients of the named provider config:
+// GetCallerIdentity on AWS, ListBuckets on S3-compatible endpoints and the provider's own check
+// otherwise
+func (c *ReadinessChecker) checkProvider(ctx context.Context, name string) error {
+	clients, err := c.cache.Get(ctx, name, "")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case clients.Manager != nil:
+		if checker, ok := clients.Manager.(provider.Checker); ok {
+			return checker.Check(ctx)
+		}
+		return nil
+	case clients.awsSession != nil:
+		if _, err := clients.STS.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+			return fmt.Errorf("STS GetCallerIdentity API call failed: %w", err)
+		}
+		return nil
+	default:
+		if _, err := clients.S3.ListBucketsWithContext(ctx, &s3.ListBucketsInput{}); err != nil {
+			return fmt.Errorf("S3 ListBuckets API call failed: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("ReadinessChecker", func() {
+	var (
+		calls   atomic.Int32
+		healthy *httptest.Server
+		broken  *httptest.Server
+		request *http.Request
+	)
+
+	// s3Compatible returns a provider config reaching an S3-compatible endpoint
+	s3Compatible := func(name, endpoint string) *s3v1alpha1.S3ProviderConfig {
+		return &s3v1alpha1.S3ProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: s3v1alpha1.S3ProviderConfigSpec{
+				Endpoint:             endpoint,
+				Region:               "us-east-1",
+				ForcePathStyle:       true,
+				CredentialsSecretRef: &s3v1alpha1.SecretReference{Name: "tenant", Namespace: "s3-acme"},
+			},
+		}
+	}
+
+	newChecker := func(ttl time.Duration, configs ...*s3v1alpha1.S3ProviderConfig) *ReadinessChecker {
+		builder := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "s3-acme"},
+			Data: map[string][]byte{
+				s3v1alpha1.CredentialsAccessKeyIDKey:     []byte("tenant"),
+				s3v1alpha1.CredentialsSecretAccessKeyKey: []byte("tenant-secret"),
+			},
+		})
+		for _, config := range configs {
+			builder = builder.WithObjects(config)
+		}
+		return NewReadinessChecker(NewCache(builder.Build(), nil), ttl)
+	}
+
+	BeforeEach(func() {
+		calls.Store(0)
+		healthy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls.Add(1)
+			_, _ = w.Write([]byte(`<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`))
+		}))
+		DeferCleanup(healthy.Close)
+		broken = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>InvalidAccessKeyId</Code><Message>The access key does not exist.</Message></Error>`))
+		}))
+		DeferCleanup(broken.Close)
+		request = httptest.NewRequest(http.MethodGet, "/readyz/object-stores", nil).WithContext(context.Background())
+	})
+
+	It("should pass when every provider accepts its credentials", func() {
+		checker := newChecker(time.Minute,
+			s3Compatible("tenant-a", healthy.URL),
+			&s3v1alpha1.S3ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+				Spec: s3v1alpha1.S3ProviderConfigSpec{
+					Type:  s3v1alpha1.ProviderLocal,
+					Local: &s3v1alpha1.LocalProviderSpec{Root: GinkgoT().TempDir()},
+				},
+			})
+		Expect(checker.Check(request)).To(Succeed())
+		Expect(calls.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should name the failing providers", func() {
+		checker := newChecker(time.Minute, s3Compatible("tenant-a", healthy.URL), s3Compatible("tenant-b", broken.URL))
+
+		err := checker.Check(request)
+		Expect(err).To(MatchError(ContainSubstring("S3ProviderConfig tenant-b: S3 ListBuckets API call failed: InvalidAccessKeyId")))
+		Expect(err.Error()).NotTo(ContainSubstring("tenant-a"))
+	})
+
+	It("should cache the results for the TTL", func() {
+		checker := newChecker(time.Minute, s3Compatible("tenant-a", healthy.URL))
+		Expect(checker.Check(request)).To(Succeed())
+		Expect(checker.Check(request)).To(Succeed())
+		Expect(calls.Load()).To(BeEquivalentTo(1))
+
+		By("checking again once the TTL elapsed")
+		checker.checked = time.Now().Add(-2 * time.Minute)
+		Expect(checker.Check(request)).To(Succeed())
+		Expect(calls.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should pass without any provider", func() {
+		Expect(newChecker(time.Minute).Check(request)).To(Succeed())
+	})
+})
@@ -0,0 +1,48 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// NewDefaultSession returns a session resolving credentials through the standard AWS provider
+// chain: static keys from the environment, a web identity token file, the shared config profile,
+// the container credentials endpoint (including EKS Pod Identity) and EC2 instance metadata.
+// It also returns the name of the provider that supplied the credentials.
+func NewDefaultSession(defaultRegion string) (*session.Session, string, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to create AWS session: %w", err)
+	}
+
+	// The region comes from AWS_REGION or the shared config profile when set
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess.Config.Region = aws.String(defaultRegion)
+	}
+
+	value, err := sess.Config.Credentials.Get()
+	if err != nil {
+		return nil, "", fmt.Errorf("no AWS credentials found in the default provider chain: %w", err)
+	}
+	return sess, value.ProviderName, nil
+}
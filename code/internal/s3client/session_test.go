@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewDefaultSession", func() {
+	// setenv sets an environment variable for the duration of the spec
+	setenv := func(key, value string) {
+		previous, had := os.LookupEnv(key)
+		if value == "" {
+			Expect(os.Unsetenv(key)).To(Succeed())
+		} else {
+			Expect(os.Setenv(key, value)).To(Succeed())
+		}
+		DeferCleanup(func() {
+			if had {
+				_ = os.Setenv(key, previous)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+
+	BeforeEach(func() {
+		for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION",
+			"AWS_DEFAULT_REGION", "AWS_PROFILE", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN"} {
+			setenv(key, "")
+		}
+		// Keep the chain away from the shared files of the machine running the tests
+		setenv("AWS_CONFIG_FILE", filepath.Join(GinkgoT().TempDir(), "config"))
+		setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(GinkgoT().TempDir(), "credentials"))
+	})
+
+	It("should use static keys from the environment", func() {
+		setenv("AWS_ACCESS_KEY_ID", "env-key")
+		setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+		setenv("AWS_REGION", "eu-west-1")
+
+		sess, source, err := NewDefaultSession("us-west-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal("EnvConfigCredentials"))
+		Expect(aws.StringValue(sess.Config.Region)).To(Equal("eu-west-1"))
+	})
+
+	It("should use the shared config profile and fall back to the default region", func() {
+		credentialsFile := filepath.Join(GinkgoT().TempDir(), "credentials")
+		Expect(os.WriteFile(credentialsFile, []byte("[operator]\naws_access_key_id = profile-key\naws_secret_access_key = profile-secret\n"), 0o600)).To(Succeed())
+		setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+		setenv("AWS_PROFILE", "operator")
+
+		sess, source, err := NewDefaultSession("us-west-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(HavePrefix("SharedConfigCredentials"))
+		Expect(aws.StringValue(sess.Config.Region)).To(Equal("us-west-2"))
+
+		value, err := sess.Config.Credentials.Get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.AccessKeyID).To(Equal("profile-key"))
+	})
+})
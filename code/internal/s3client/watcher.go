@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultWatchInterval is how often a credentials file is checked for changes
+const defaultWatchInterval = 10 * time.Second
+
+// CredentialsFileWatcher rebuilds the operator session of a Cache whenever a mounted
+// credentials file changes, so rotated keys are picked up without a restart.
+// Files are polled because Secret volumes are updated through symlink swaps.
+type CredentialsFileWatcher struct {
+	// Path is the credentials file to watch
+	Path string
+	// Interval between checks, defaults to 10 seconds
+	Interval time.Duration
+	// Cache receives the rebuilt session
+	Cache *Cache
+	// NewSession builds the operator session from the current file contents
+	NewSession func() (*session.Session, string, error)
+	// Recorder and EventTarget, both optional, emit an event on every rotation
+	Recorder    record.EventRecorder
+	EventTarget runtime.Object
+}
+
+// Start polls the credentials file until ctx is done. It implements manager.Runnable.
+func (w *CredentialsFileWatcher) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("credentials-watcher").WithValues("Path", w.Path)
+
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	last, err := fileDigest(w.Path)
+	if err != nil {
+		log.Error(err, "Failed to read credentials file, waiting for it to appear")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		digest, err := fileDigest(w.Path)
+		if err != nil {
+			log.Error(err, "Failed to read credentials file")
+			continue
+		}
+		if digest == last {
+			continue
+		}
+
+		sess, source, err := w.NewSession()
+		if err != nil {
+			// Keep the previous credentials and retry on the next change
+			log.Error(err, "Failed to load rotated credentials")
+			last = digest
+			continue
+		}
+		last = digest
+
+		w.Cache.SetFallback(sess)
+		RecordRotation(OperatorCredentialsSource, time.Now())
+		log.Info("Operator AWS credentials rotated", "source", source)
+		if w.Recorder != nil && w.EventTarget != nil {
+			w.Recorder.Eventf(w.EventTarget, corev1.EventTypeNormal, "CredentialsRotated",
+				"Operator AWS credentials reloaded from %s", w.Path)
+		}
+	}
+}
+
+// NeedLeaderElection returns false so every replica picks up rotated credentials
+func (w *CredentialsFileWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// fileDigest returns the SHA-256 digest of a file
+func fileDigest(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
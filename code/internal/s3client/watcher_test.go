@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CredentialsFileWatcher", func() {
+	It("should swap the operator session when the credentials file changes", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "credentials")
+		Expect(os.WriteFile(path, []byte("first"), 0o600)).To(Succeed())
+
+		// The file holds the access key ID, enough to tell sessions apart
+		newSession := func() (*session.Session, string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, "", err
+			}
+			sess, err := session.NewSession(&aws.Config{
+				Region:      aws.String("us-west-2"),
+				Credentials: credentials.NewStaticCredentials(strings.TrimSpace(string(data)), "secret", ""),
+			})
+			return sess, credentials.StaticProviderName, err
+		}
+		accessKeyID := func(cache *Cache) string {
+			clients, err := cache.Get(context.Background(), "", "")
+			Expect(err).NotTo(HaveOccurred())
+			value, err := clients.S3.Config.Credentials.Get()
+			Expect(err).NotTo(HaveOccurred())
+			return value.AccessKeyID
+		}
+
+		sess, _, err := newSession()
+		Expect(err).NotTo(HaveOccurred())
+		cache := NewCache(fake.NewClientBuilder().WithScheme(scheme()).Build(), sess)
+		before, err := cache.Get(context.Background(), "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := record.NewFakeRecorder(10)
+		watcher := &CredentialsFileWatcher{
+			Path:        path,
+			Interval:    10 * time.Millisecond,
+			Cache:       cache,
+			NewSession:  newSession,
+			Recorder:    recorder,
+			EventTarget: &corev1.ObjectReference{Kind: "Pod", Name: "controller-manager", Namespace: "system"},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(watcher.Start(ctx)).To(Succeed())
+		}()
+
+		Consistently(func() string { return accessKeyID(cache) }, 50*time.Millisecond).Should(Equal("first"))
+
+		Expect(os.WriteFile(path, []byte("second"), 0o600)).To(Succeed())
+		Eventually(func() string { return accessKeyID(cache) }).Should(Equal("second"))
+		Eventually(recorder.Events).Should(Receive(ContainSubstring("CredentialsRotated")))
+
+		// Clients handed out before the rotation keep their credentials
+		value, err := before.S3.Config.Credentials.Get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.AccessKeyID).To(Equal("first"))
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// awsSpanKey holds the span of an AWS API call in the context of its request
+type awsSpanKey struct{}
+
+var (
+	// startAWSHandler starts the span of an AWS API call. It belongs to the Validate handlers,
+	// which run once per call before the first attempt
+	startAWSHandler = request.NamedHandler{
+		Name: "kube-s3-operator.tracing.StartAWSHandler",
+		Fn:   startAWSSpan,
+	}
+	// endAWSHandler ends the span of an AWS API call after its last retry
+	endAWSHandler = request.NamedHandler{
+		Name: "kube-s3-operator.tracing.EndAWSHandler",
+		Fn:   endAWSSpan,
+	}
+)
+
+// InstrumentAWS traces every API call made through the handlers of an AWS client, e.g.
+// s3.S3.Handlers. The spans are children of the span in the context of the call
+func InstrumentAWS(handlers *request.Handlers) {
+	handlers.Validate.PushFrontNamed(startAWSHandler)
+	handlers.Complete.PushBackNamed(endAWSHandler)
+}
+
+// startAWSSpan starts the span of one AWS API call
+func startAWSSpan(r *request.Request) {
+	attributes := []attribute.KeyValue{
+		semconv.RPCSystemKey.String("aws-api"),
+		semconv.RPCService(r.ClientInfo.ServiceID),
+		semconv.RPCMethod(r.Operation.Name),
+		semconv.CloudRegion(aws.StringValue(r.Config.Region)),
+	}
+	if values, err := awsutil.ValuesAtPath(r.Params, "Bucket"); err == nil && len(values) == 1 {
+		if bucket, ok := values[0].(*string); ok {
+			attributes = append(attributes, semconv.AWSS3Bucket(aws.StringValue(bucket)))
+		}
+	}
+
+	ctx, span := Tracer().Start(r.Context(), r.ClientInfo.ServiceID+"."+r.Operation.Name,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	r.SetContext(context.WithValue(ctx, awsSpanKey{}, span))
+}
+
+// endAWSSpan ends the span of one AWS API call with its request ID, status and retries
+func endAWSSpan(r *request.Request) {
+	span, ok := r.Context().Value(awsSpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.Int("aws.retry_count", r.RetryCount))
+	if r.RequestID != "" {
+		span.SetAttributes(semconv.AWSRequestID(r.RequestID))
+	}
+	if r.HTTPResponse != nil && r.HTTPResponse.StatusCode != 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(r.HTTPResponse.StatusCode))
+	}
+	if aerr, ok := r.Error.(awserr.Error); ok {
+		span.SetAttributes(attribute.String("aws.error_code", aerr.Code()))
+	}
+	End(span, r.Error)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// tracedClient traces the writes of a client; reads are served by the cache and not traced
+type tracedClient struct {
+	client.Client
+}
+
+// WrapClient returns a client tracing every Kubernetes write as a child span of the context
+func WrapClient(c client.Client) client.Client {
+	return tracedClient{Client: c}
+}
+
+func (c tracedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, span := startWrite(ctx, c.Scheme(), "Create", "", obj)
+	err := c.Client.Create(ctx, obj, opts...)
+	End(span, err)
+	return err
+}
+
+func (c tracedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, span := startWrite(ctx, c.Scheme(), "Update", "", obj)
+	err := c.Client.Update(ctx, obj, opts...)
+	End(span, err)
+	return err
+}
+
+func (c tracedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, span := startWrite(ctx, c.Scheme(), "Patch", "", obj)
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	End(span, err)
+	return err
+}
+
+func (c tracedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, span := startWrite(ctx, c.Scheme(), "Delete", "", obj)
+	err := c.Client.Delete(ctx, obj, opts...)
+	End(span, err)
+	return err
+}
+
+func (c tracedClient) Status() client.SubResourceWriter {
+	return tracedStatusWriter{SubResourceWriter: c.Client.Status(), scheme: c.Scheme()}
+}
+
+// tracedStatusWriter traces the status writes of a client
+type tracedStatusWriter struct {
+	client.SubResourceWriter
+	scheme *runtime.Scheme
+}
+
+func (w tracedStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, span := startWrite(ctx, w.scheme, "Update", "status", obj)
+	err := w.SubResourceWriter.Update(ctx, obj, opts...)
+	End(span, err)
+	return err
+}
+
+func (w tracedStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	ctx, span := startWrite(ctx, w.scheme, "Patch", "status", obj)
+	err := w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+	End(span, err)
+	return err
+}
+
+// startWrite starts the span of a write, named after the verb and kind, e.g. "k8s.Update ConfigMap"
+func startWrite(ctx context.Context, scheme *runtime.Scheme, verb, subResource string, obj client.Object) (context.Context, trace.Span) {
+	kind := "Object"
+	if gvk, err := apiutil.GVKForObject(obj, scheme); err == nil {
+		kind = gvk.Kind
+	}
+	name := "k8s." + verb + " " + kind
+	attributes := []attribute.KeyValue{
+		attribute.String("k8s.kind", kind),
+		attribute.String("k8s.object.name", obj.GetName()),
+		semconv.K8SNamespaceName(obj.GetNamespace()),
+	}
+	if subResource != "" {
+		name += "/" + subResource
+		attributes = append(attributes, attribute.String("k8s.subresource", subResource))
+	}
+	return Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing exports OpenTelemetry traces of the reconciles over OTLP, with child spans for
+// the AWS API calls and the Kubernetes writes they make.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// instrumentationName names the tracer of the operator
+	instrumentationName = "github.com/victorbecerragit/kube-s3-operator/code"
+	// serviceName is the default service.name of the spans, overridden by OTEL_SERVICE_NAME
+	serviceName = "kube-s3-operator"
+	// shutdownTimeout bounds the export of the last spans when the operator stops
+	shutdownTimeout = 5 * time.Second
+)
+
+// Options configure the export of traces
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint string
+	// Insecure disables TLS towards the collector
+	Insecure bool
+	// SampleRatio is the fraction of reconciles traced, between 0 and 1
+	SampleRatio float64
+}
+
+// Provider exports the spans of the operator. It is a manager Runnable that flushes the
+// remaining spans when the manager stops
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Setup installs a global tracer provider exporting spans to the OTLP collector of opts
+func Setup(ctx context.Context, opts Options) (*Provider, error) {
+	exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return &Provider{provider: provider}, nil
+}
+
+// Start waits for the manager to stop and flushes the remaining spans
+func (p *Provider) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return p.provider.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection lets every replica export its own spans
+func (p *Provider) NeedLeaderElection() bool {
+	return false
+}
+
+// Tracer returns the tracer of the operator. Without Setup, its spans are not recorded
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartReconcile starts the span of a reconcile and adds its trace and span IDs to the logger
+// of the returned context, so log lines can be matched with the trace
+func StartReconcile(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+	if spanContext := span.SpanContext(); spanContext.IsValid() {
+		log := logf.FromContext(ctx).WithValues("traceID", spanContext.TraceID().String(), "spanID", spanContext.SpanID().String())
+		ctx = logf.IntoContext(ctx, log)
+	}
+	return ctx, span
+}
+
+// End records err, if any, on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
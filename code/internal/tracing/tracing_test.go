@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		ctx   context.Context
+		spans *tracetest.SpanRecorder
+	)
+
+	// attributes returns the attributes of a span by key
+	attributes := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		values := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			values[kv.Key] = kv.Value
+		}
+		return values
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		spans = tracetest.NewSpanRecorder()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		DeferCleanup(otel.SetTracerProvider, previous)
+	})
+
+	It("should add the trace ID to the log lines of a reconcile", func() {
+		var logged string
+		ctx = logf.IntoContext(ctx, funcr.New(func(_, args string) { logged = args }, funcr.Options{}))
+
+		ctx, span := StartReconcile(ctx, "S3Bucket.Reconcile", attribute.String("s3bucket.name", "data"))
+		logf.FromContext(ctx).Info("Reconciling S3Bucket")
+		End(span, nil)
+
+		Expect(logged).To(ContainSubstring(`"traceID"="` + span.SpanContext().TraceID().String() + `"`))
+		Expect(spans.Ended()).To(HaveLen(1))
+		Expect(spans.Ended()[0].Name()).To(Equal("S3Bucket.Reconcile"))
+	})
+
+	It("should trace AWS API calls as children of the reconcile", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("x-amz-request-id", "4442587FB7D0A2F9")
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		DeferCleanup(server.Close)
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String("eu-west-1"),
+			S3ForcePathStyle: aws.Bool(true),
+			MaxRetries:       aws.Int(0),
+			Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		client := s3.New(sess)
+		InstrumentAWS(&client.Handlers)
+
+		ctx, parent := StartReconcile(ctx, "S3Bucket.Reconcile")
+		_, err = client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String("my-data-bucket")})
+		Expect(err).To(HaveOccurred())
+		End(parent, err)
+
+		Expect(spans.Ended()).To(HaveLen(2))
+		call := spans.Ended()[0]
+		Expect(call.Name()).To(Equal("S3.HeadBucket"))
+		Expect(call.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(call.Status().Code).To(Equal(codes.Error))
+		Expect(attributes(call)).To(HaveKeyWithValue(attribute.Key("aws.s3.bucket"), attribute.StringValue("my-data-bucket")))
+		Expect(attributes(call)).To(HaveKeyWithValue(attribute.Key("cloud.region"), attribute.StringValue("eu-west-1")))
+		Expect(attributes(call)).To(HaveKeyWithValue(attribute.Key("aws.request_id"), attribute.StringValue("4442587FB7D0A2F9")))
+		Expect(attributes(call)).To(HaveKeyWithValue(attribute.Key("http.response.status_code"), attribute.IntValue(http.StatusNotFound)))
+	})
+
+	It("should trace Kubernetes writes", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c := WrapClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "data-s3-cm", Namespace: "default"}}
+
+		Expect(c.Create(ctx, cm)).To(Succeed())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		cm.Data = map[string]string{"BucketName": "my-data-bucket"}
+		Expect(c.Update(ctx, cm)).To(Succeed())
+		Expect(c.Delete(ctx, cm)).To(Succeed())
+
+		var names []string
+		for _, span := range spans.Ended() {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(Equal([]string{"k8s.Create ConfigMap", "k8s.Update ConfigMap", "k8s.Delete ConfigMap"}))
+		Expect(attributes(spans.Ended()[0])).To(HaveKeyWithValue(attribute.Key("k8s.namespace.name"), attribute.StringValue("default")))
+		Expect(attributes(spans.Ended()[0])).To(HaveKeyWithValue(attribute.Key("k8s.object.name"), attribute.StringValue("data-s3-cm")))
+	})
+
+	It("should trace status writes", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+		c := WrapClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).WithStatusSubresource(pod).Build())
+
+		pod.Status.Phase = corev1.PodRunning
+		Expect(c.Status().Update(ctx, pod)).To(Succeed())
+
+		Expect(spans.Ended()).To(HaveLen(1))
+		Expect(spans.Ended()[0].Name()).To(Equal("k8s.Update Pod/status"))
+	})
+})
@@ -0,0 +1,227 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var podlog = logf.Log.WithName("pod-resource")
+
+// Objects written for every S3Bucket by its controller
+const (
+	configMapName         = "%s-s3-cm"
+	credentialsSecretName = "%s-s3-credentials"
+	connectionSecretName  = "%s-s3-connection"
+)
+
+const (
+	// volumeMountRoot is the directory the buckets are mounted under in volume mode
+	volumeMountRoot = "/var/run/s3"
+	// volumePrefix prefixes the names of the injected volumes
+	volumePrefix = "s3-"
+)
+
+// envPrefixInvalid matches the characters that are replaced in environment variable prefixes
+var envPrefixInvalid = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// SetupPodWebhookWithManager registers the webhook for Pod in the manager.
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithDefaulter(&PodCustomDefaulter{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// The webhook fails closed for the Pods it receives. The marker cannot express selectors, so
+// config/webhook/selector_patch.yaml only sends it Pods labeled s3v1alpha1.InjectLabel outside
+// kube-system and the namespace of the operator
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-v1.kb.io,admissionReviewVersions=v1
+
+// PodCustomDefaulter injects the connection details of the S3Buckets listed in the
+// s3.acme.io/inject annotation of a Pod labeled s3.acme.io/inject-enabled=true. Other Pods
+// are left untouched
+type PodCustomDefaulter struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomDefaulter = &PodCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Pod.
+func (d *PodCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+	// The selector of the webhook configuration already filters unlabeled Pods, unless it was left out
+	if pod.Labels[s3v1alpha1.InjectLabel] != "true" {
+		return nil
+	}
+	buckets := injectedBuckets(pod.Annotations[s3v1alpha1.InjectAnnotation])
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	mode := pod.Annotations[s3v1alpha1.InjectModeAnnotation]
+	if mode == "" {
+		mode = s3v1alpha1.InjectModeEnv
+	}
+	if mode != s3v1alpha1.InjectModeEnv && mode != s3v1alpha1.InjectModeVolume {
+		return fmt.Errorf("annotation %s must be %s or %s, not %q", s3v1alpha1.InjectModeAnnotation,
+			s3v1alpha1.InjectModeEnv, s3v1alpha1.InjectModeVolume, mode)
+	}
+
+	// Pods created through controllers only get their namespace from the request
+	namespace := pod.Namespace
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Namespace != "" {
+		namespace = req.Namespace
+	}
+
+	// Every bucket must be ready, so the Pod never starts with missing connection details
+	for _, name := range buckets {
+		s3bkt := &s3v1alpha1.S3Bucket{}
+		if err := d.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, s3bkt); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("S3Bucket %s/%s requested by annotation %s does not exist", namespace, name, s3v1alpha1.InjectAnnotation)
+			}
+			return fmt.Errorf("failed to get S3Bucket %s/%s: %w", namespace, name, err)
+		}
+		if s3bkt.Status.State != s3v1alpha1.CREATED_STATE {
+			state := s3bkt.Status.State
+			if state == "" {
+				state = s3v1alpha1.PENDING_STATE
+			}
+			return fmt.Errorf("S3Bucket %s/%s requested by annotation %s is not ready: its state is %s, not %s",
+				namespace, name, s3v1alpha1.InjectAnnotation, state, s3v1alpha1.CREATED_STATE)
+		}
+	}
+
+	podlog.Info("Injecting S3Bucket connection details", "Namespace", namespace, "Buckets", buckets, "Mode", mode)
+	if mode == s3v1alpha1.InjectModeVolume {
+		injectVolumes(&pod.Spec, buckets)
+	} else {
+		injectEnv(&pod.Spec, buckets)
+	}
+	return nil
+}
+
+// injectedBuckets parses the s3.acme.io/inject annotation, dropping duplicates
+func injectedBuckets(annotation string) []string {
+	var buckets []string
+	for _, name := range strings.Split(annotation, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(buckets, name) {
+			buckets = append(buckets, name)
+		}
+	}
+	return buckets
+}
+
+// volumeName returns the name of the volume of a bucket, a DNS label unlike S3Bucket names
+func volumeName(bucket string) string {
+	name := volumePrefix + strings.ReplaceAll(bucket, ".", "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// injectEnv adds the ConfigMap and Secrets of the buckets to the environment of every container.
+// With several buckets the variables are prefixed with the bucket name, e.g. MY_BUCKET_BucketName
+func injectEnv(spec *corev1.PodSpec, buckets []string) {
+	var sources []corev1.EnvFromSource
+	for _, name := range buckets {
+		prefix := ""
+		if len(buckets) > 1 {
+			prefix = envPrefixInvalid.ReplaceAllString(strings.ToUpper(name), "_") + "_"
+		}
+		optional := true
+		sources = append(sources,
+			corev1.EnvFromSource{Prefix: prefix, ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(configMapName, name)},
+			}},
+			corev1.EnvFromSource{Prefix: prefix, SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(credentialsSecretName, name)},
+				Optional:             &optional,
+			}},
+			corev1.EnvFromSource{Prefix: prefix, SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(connectionSecretName, name)},
+				Optional:             &optional,
+			}},
+		)
+	}
+
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			containers[i].EnvFrom = append(containers[i].EnvFrom, sources...)
+		}
+	}
+}
+
+// injectVolumes mounts the ConfigMap and Secrets of each bucket at /var/run/s3/<bucket> in every container
+func injectVolumes(spec *corev1.PodSpec, buckets []string) {
+	var mounts []corev1.VolumeMount
+	for _, name := range buckets {
+		optional := true
+		volume := corev1.Volume{
+			Name: volumeName(name),
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(configMapName, name)},
+					}},
+					{Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(credentialsSecretName, name)},
+						Optional:             &optional,
+					}},
+					{Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(connectionSecretName, name)},
+						Optional:             &optional,
+					}},
+				},
+			}},
+		}
+		spec.Volumes = append(spec.Volumes, volume)
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: path.Join(volumeMountRoot, name),
+			ReadOnly:  true,
+		})
+	}
+
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, mounts...)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	s3v1alpha1 "github.com/victorbecerragit/kube-s3-operator/code/api/v1alpha1"
+)
+
+var _ = Describe("Pod Webhook", func() {
+	var (
+		ctx       context.Context
+		defaulter *PodCustomDefaulter
+		pod       *corev1.Pod
+	)
+
+	bucket := func(name, state string) *s3v1alpha1.S3Bucket {
+		return &s3v1alpha1.S3Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec:       s3v1alpha1.S3BucketSpec{Name: name + "-2025"},
+			Status:     s3v1alpha1.S3BucketStatus{State: state},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(s3v1alpha1.AddToScheme(scheme)).To(Succeed())
+		defaulter = &PodCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			bucket("reports", s3v1alpha1.CREATED_STATE),
+			bucket("media.assets", s3v1alpha1.CREATED_STATE),
+			bucket("pending", s3v1alpha1.CREATING_STATE),
+		).Build()}
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app",
+				Namespace:   "apps",
+				Labels:      map[string]string{s3v1alpha1.InjectLabel: "true"},
+				Annotations: map[string]string{},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate"}},
+				Containers:     []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+			},
+		}
+	})
+
+	It("should leave Pods without the annotation untouched", func() {
+		original := pod.DeepCopy()
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(pod).To(Equal(original))
+	})
+
+	It("should leave Pods that did not opt in untouched", func() {
+		delete(pod.Labels, s3v1alpha1.InjectLabel)
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "missing"
+		original := pod.DeepCopy()
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(pod).To(Equal(original))
+	})
+
+	It("should add the ConfigMap and Secrets of a bucket to every container", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "reports"
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			Expect(container.EnvFrom).To(HaveLen(3))
+			Expect(container.EnvFrom[0].Prefix).To(BeEmpty())
+			Expect(container.EnvFrom[0].ConfigMapRef.Name).To(Equal("reports-s3-cm"))
+			Expect(container.EnvFrom[1].SecretRef.Name).To(Equal("reports-s3-credentials"))
+			Expect(*container.EnvFrom[1].SecretRef.Optional).To(BeTrue())
+			Expect(container.EnvFrom[2].SecretRef.Name).To(Equal("reports-s3-connection"))
+		}
+		Expect(pod.Spec.Volumes).To(BeEmpty())
+	})
+
+	It("should prefix the variables of several buckets", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "reports, media.assets,reports"
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+
+		envFrom := pod.Spec.Containers[0].EnvFrom
+		Expect(envFrom).To(HaveLen(6))
+		Expect(envFrom[0].Prefix).To(Equal("REPORTS_"))
+		Expect(envFrom[3].Prefix).To(Equal("MEDIA_ASSETS_"))
+		Expect(envFrom[3].ConfigMapRef.Name).To(Equal("media.assets-s3-cm"))
+	})
+
+	It("should mount the buckets in volume mode", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "reports,media.assets"
+		pod.Annotations[s3v1alpha1.InjectModeAnnotation] = s3v1alpha1.InjectModeVolume
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+
+		Expect(pod.Spec.Volumes).To(HaveLen(2))
+		Expect(pod.Spec.Volumes[1].Name).To(Equal("s3-media-assets"))
+		sources := pod.Spec.Volumes[0].Projected.Sources
+		Expect(sources).To(HaveLen(3))
+		Expect(sources[0].ConfigMap.Name).To(Equal("reports-s3-cm"))
+		Expect(sources[1].Secret.Name).To(Equal("reports-s3-credentials"))
+
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			Expect(container.VolumeMounts).To(HaveLen(2))
+			Expect(container.VolumeMounts[0].MountPath).To(Equal("/var/run/s3/reports"))
+			Expect(container.VolumeMounts[1].MountPath).To(Equal("/var/run/s3/media.assets"))
+			Expect(container.EnvFrom).To(BeEmpty())
+		}
+	})
+
+	It("should reject buckets that are not ready", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "reports,pending"
+		Expect(defaulter.Default(ctx, pod)).To(MatchError(
+			"S3Bucket apps/pending requested by annotation s3.acme.io/inject is not ready: its state is CREATING, not CREATED"))
+		Expect(pod.Spec.Containers[0].EnvFrom).To(BeEmpty())
+	})
+
+	It("should reject buckets that do not exist", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "missing"
+		Expect(defaulter.Default(ctx, pod)).To(MatchError(ContainSubstring("S3Bucket apps/missing requested by annotation s3.acme.io/inject does not exist")))
+	})
+
+	It("should reject unknown injection modes", func() {
+		pod.Annotations[s3v1alpha1.InjectAnnotation] = "reports"
+		pod.Annotations[s3v1alpha1.InjectModeAnnotation] = "files"
+		Expect(defaulter.Default(ctx, pod)).To(MatchError(ContainSubstring("must be env or volume")))
+	})
+})